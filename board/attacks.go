@@ -0,0 +1,129 @@
+package board
+
+import "math/bits"
+
+// squares are numbered y * 8 + x like the bits of the piece sets
+
+var knightJumps = [8][2]int{{1, 2}, {2, 1}, {2, -1}, {1, -2}, {-1, -2}, {-2, -1}, {-2, 1}, {-1, 2}}
+var kingSteps = [8][2]int{{1, 0}, {1, 1}, {0, 1}, {-1, 1}, {-1, 0}, {-1, -1}, {0, -1}, {1, -1}}
+var rookDirections = [4][2]int{{1, 0}, {-1, 0}, {0, 1}, {0, -1}}
+var bishopDirections = [4][2]int{{1, 1}, {1, -1}, {-1, 1}, {-1, -1}}
+
+func onBoard(x, y int) bool {
+	return x >= 0 && x < 8 && y >= 0 && y < 8
+}
+
+func stepAttacks(square int, steps [][2]int) uint64 {
+	x, y := square%8, square/8
+	var attacks uint64
+	for _, step := range steps {
+		if onBoard(x+step[0], y+step[1]) {
+			attacks |= squareMask(x+step[0], y+step[1])
+		}
+	}
+	return attacks
+}
+
+func knightAttacks(square int) uint64 {
+	return knightTable[square]
+}
+
+func kingAttacks(square int) uint64 {
+	return kingTable[square]
+}
+
+// pawnAttacks are the fields a pawn of the given color on square captures on
+func pawnAttacks(square int, white bool) uint64 {
+	if white {
+		return pawnTable[1][square]
+	}
+	return pawnTable[0][square]
+}
+
+// slidingAttacks follows every direction up to and including the first occupied field. It's slow and only used to
+// fill the magic tables.
+func slidingAttacks(square int, occupied uint64, directions [4][2]int) uint64 {
+	x, y := square%8, square/8
+	var attacks uint64
+	for _, direction := range directions {
+		for i, j := x+direction[0], y+direction[1]; onBoard(i, j); i, j = i+direction[0], j+direction[1] {
+			attacks |= squareMask(i, j)
+			if occupied&squareMask(i, j) != 0 {
+				break
+			}
+		}
+	}
+	return attacks
+}
+
+func rookAttacks(square int, occupied uint64) uint64 {
+	return rookMagics[square].lookup(occupied)
+}
+
+func bishopAttacks(square int, occupied uint64) uint64 {
+	return bishopMagics[square].lookup(occupied)
+}
+
+// attackersTo returns the pieces of both colors attacking square, with sliders blocked by occupied instead of the
+// actual occupancy. Taking pieces out of occupied reveals the x-ray attackers behind them.
+func (board *BitBoard) attackersTo(square int, occupied uint64) uint64 {
+	rooks := board.pieces[WHITE_ROOK] | board.pieces[BLACK_ROOK] | board.pieces[WHITE_QUEEN] | board.pieces[BLACK_QUEEN]
+	bishops := board.pieces[WHITE_BISHOP] | board.pieces[BLACK_BISHOP] | board.pieces[WHITE_QUEEN] | board.pieces[BLACK_QUEEN]
+
+	// a white pawn attacks square if a black pawn on square would attack the pawn
+	return pawnAttacks(square, false)&board.pieces[WHITE_PAWN] |
+		pawnAttacks(square, true)&board.pieces[BLACK_PAWN] |
+		knightAttacks(square)&(board.pieces[WHITE_KNIGHT]|board.pieces[BLACK_KNIGHT]) |
+		kingAttacks(square)&(board.pieces[WHITE_KING]|board.pieces[BLACK_KING]) |
+		rookAttacks(square, occupied)&rooks |
+		bishopAttacks(square, occupied)&bishops
+}
+
+// AttackersTo returns the pieces of the given side attacking square, no matter what stands on square.
+// Pawns attack diagonally only, a pawn in front of square doesn't attack it.
+func (board *BitBoard) AttackersTo(square int, white bool) uint64 {
+	own := board.black
+	if white {
+		own = board.white
+	}
+	return board.attackersTo(square, board.occupied) & own
+}
+
+// AttackedSquares returns all fields at least one piece of the given side attacks, including fields of its own pieces
+func (board *BitBoard) AttackedSquares(white bool) uint64 {
+	offset := Piece(0)
+	if white {
+		offset = 6
+	}
+
+	var attacked uint64
+	for pieces := board.pieces[BLACK_PAWN+offset]; pieces != 0; pieces &= pieces - 1 {
+		attacked |= pawnAttacks(bits.TrailingZeros64(pieces), white)
+	}
+	for pieces := board.pieces[BLACK_KNIGHT+offset]; pieces != 0; pieces &= pieces - 1 {
+		attacked |= knightAttacks(bits.TrailingZeros64(pieces))
+	}
+	for pieces := board.pieces[BLACK_KING+offset]; pieces != 0; pieces &= pieces - 1 {
+		attacked |= kingAttacks(bits.TrailingZeros64(pieces))
+	}
+	for pieces := board.pieces[BLACK_ROOK+offset] | board.pieces[BLACK_QUEEN+offset]; pieces != 0; pieces &= pieces - 1 {
+		attacked |= rookAttacks(bits.TrailingZeros64(pieces), board.occupied)
+	}
+	for pieces := board.pieces[BLACK_BISHOP+offset] | board.pieces[BLACK_QUEEN+offset]; pieces != 0; pieces &= pieces - 1 {
+		attacked |= bishopAttacks(bits.TrailingZeros64(pieces), board.occupied)
+	}
+
+	return attacked
+}
+
+// KingZone returns the field of the king of the given side and the fields around it, 0 without a king
+func (board *BitBoard) KingZone(white bool) uint64 {
+	king := board.pieces[BLACK_KING]
+	if white {
+		king = board.pieces[WHITE_KING]
+	}
+	if king == 0 {
+		return 0
+	}
+	return king | kingAttacks(bits.TrailingZeros64(king))
+}
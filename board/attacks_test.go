@@ -0,0 +1,94 @@
+package board
+
+import "testing"
+
+// attackersFromMatrices finds the attackers the slow way, a piece of the other side on the field lets pawns capture
+func attackersFromMatrices(board *BitBoard, square int, white bool) uint64 {
+	x, y := square%8, square/8
+	target := board.Copy()
+	if white {
+		target.PlacePieceOnBoard(x, y, BLACK_PAWN)
+	} else {
+		target.PlacePieceOnBoard(x, y, WHITE_PAWN)
+	}
+
+	own := target.black
+	if white {
+		own = target.white
+	}
+
+	var attackers uint64
+	for i := 0; i < 64; i++ {
+		if own&(1<<uint(i)) == 0 {
+			continue
+		}
+		piece := target.GetPieceOnField(i%8, i/8)
+		if matrix := piece.GetMovementMatrix(&target, i%8, i/8, true); !matrix.isFieldEmpty(x, y) {
+			attackers |= 1 << uint(i)
+		}
+	}
+	return attackers
+}
+
+func TestBitBoard_AttackersTo(t *testing.T) {
+	for _, position := range perftPositions {
+		board := FromFEN(position.fen)
+		for square := 0; square < 64; square++ {
+			for _, white := range []bool{true, false} {
+				if expected := attackersFromMatrices(&board, square, white); board.AttackersTo(square, white) != expected {
+					t.Errorf("wrong attackers of %s for white %v in %s", RowColToAlgebra(square%8, square/8), white, position.fen)
+				}
+			}
+		}
+	}
+
+	// pawns attack diagonally, the one in front doesn't
+	board := FromFEN("4k3/8/8/8/3p4/3P4/8/4K3 w - - 0 1")
+	if board.AttackersTo(3*8+3, true) != 0 || board.AttackersTo(2*8+4, false) != squareMask(3, 3) {
+		t.Error("wrong pawn attacks")
+	}
+
+	// x-rays show up once the piece in front is gone
+	board = FromFEN("4r1k1/8/8/4p3/8/8/4R3/4R1K1 w - - 0 1")
+	e5 := 4*8 + 4
+	if board.attackersTo(e5, board.occupied) != squareMask(4, 1)|squareMask(4, 7) {
+		t.Error("only the front rooks should attack e5")
+	}
+	if board.attackersTo(e5, board.occupied&^squareMask(4, 1))&squareMask(4, 0) == 0 {
+		t.Error("rook behind the rook should attack e5")
+	}
+}
+
+func TestBitBoard_AttackedSquares(t *testing.T) {
+	for _, position := range perftPositions {
+		board := FromFEN(position.fen)
+		for _, white := range []bool{true, false} {
+			var expected uint64
+			for square := 0; square < 64; square++ {
+				if board.AttackersTo(square, white) != 0 {
+					expected |= 1 << uint(square)
+				}
+			}
+			if board.AttackedSquares(white) != expected {
+				t.Errorf("wrong attacked squares for white %v in %s", white, position.fen)
+			}
+		}
+	}
+
+	board := GetStartBoard()
+	if board.AttackedSquares(true)&0xFF0000 != 0xFF0000 || board.AttackedSquares(true)&0xFF000000 != 0 {
+		t.Error("white should attack the third rank and nothing beyond")
+	}
+}
+
+func TestBitBoard_KingZone(t *testing.T) {
+	board := GetStartBoard()
+	if board.KingZone(true) != squareMask(3, 0)|squareMask(4, 0)|squareMask(5, 0)|squareMask(3, 1)|squareMask(4, 1)|squareMask(5, 1) {
+		t.Error("wrong king zone for the white king")
+	}
+
+	empty := CreateEmptyBitBoard()
+	if empty.KingZone(false) != 0 {
+		t.Error("no king, no zone")
+	}
+}
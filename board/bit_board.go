@@ -1,12 +1,16 @@
 package board
 
 import (
+	"math/bits"
 	"strconv"
-	"strings"
 )
 
+// every piece type has its own set, bit y * 8 + x marks the field (x, y)
 type BitBoard struct {
-	board            [][][]bool
+	pieces           [12]uint64
+	white            uint64
+	black            uint64
+	occupied         uint64
 	blackCastleQueen bool
 	blackCastleKing  bool
 	whiteCastleQueen bool
@@ -14,7 +18,8 @@ type BitBoard struct {
 	whitesTurn       bool
 	turn             int
 	halfmove         int
-	enPassant        []int
+	enPassant        [2]int
+	hash             uint64
 }
 
 func (board *BitBoard) GetTurn() int {
@@ -25,29 +30,50 @@ func (board *BitBoard) SetTurn(turn int) {
 	board.turn = turn
 }
 
+func (board *BitBoard) GetHalfmove() int {
+	return board.halfmove
+}
+
 func (board *BitBoard) SetWhitesTurn(whitesTurn bool) {
+	if board.whitesTurn != whitesTurn {
+		board.hash ^= whitesTurnKey
+	}
+	board.hash ^= board.enPassantHash()
 	board.whitesTurn = whitesTurn
+	board.hash ^= board.enPassantHash()
 }
 
 func CreateEmptyBitBoard() BitBoard {
-	board := make([][][]bool, 8)
-	for i := 0; i < 8; i++ {
-		row := make([][]bool, 8)
-		for j := 0; j < 8; j++ {
-			field := make([]bool, 12)
-			row[j] = field
-		}
-		board[i] = row
-	}
+	board := BitBoard{
+		blackCastleQueen: true,
+		blackCastleKing:  true,
+		whiteCastleQueen: true,
+		whiteCastleKing:  true,
+		whitesTurn:       true,
+		enPassant:        [2]int{-1, -1},
+	}
+	board.hash = board.computeHash()
+	return board
+}
 
-	return BitBoard{board, true, true, true, true, true, 0, 0, []int{-1, -1}}
+func squareMask(x, y int) uint64 {
+	return 1 << uint(y*8+x)
+}
+
+func (board *BitBoard) updateOccupancy() {
+	board.white = 0
+	board.black = 0
+	for i := 0; i < 6; i++ {
+		board.black |= board.pieces[i]
+		board.white |= board.pieces[i+6]
+	}
+	board.occupied = board.white | board.black
 }
 
 func GetStartBoard() BitBoard {
 	return FromFEN("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1")
 }
 
-// TODO: halfmove clock and en passant field
 // [board (as usual)] [current side] [castle rights] [en passant field] [halfmove clock] [turn]
 func (board *BitBoard) ToFEN() string {
 	output := ""
@@ -82,7 +108,7 @@ func (board *BitBoard) ToFEN() string {
 	output += " "
 
 	// active color
-	if board.turn % 2 == 1 {
+	if board.whitesTurn {
 		output += "w"
 	} else {
 		output += "b"
@@ -124,124 +150,81 @@ func (board *BitBoard) ToFEN() string {
 	return output
 }
 
+// FromFEN is meant for FENs known to be valid, it panics otherwise
 func FromFEN(fen string) BitBoard {
-    board := CreateEmptyBitBoard()
-
-    fenSplitBySpace := strings.Split(fen, " ")
-    boardString := fenSplitBySpace[0]
-    currentSide := fenSplitBySpace[1]
-    castleRights := fenSplitBySpace[2]
-    enPassant := fenSplitBySpace[3]
-	halfmove, _ := strconv.Atoi(fenSplitBySpace[4])
-	turn, _ := strconv.Atoi(fenSplitBySpace[5])
-
-    rows := strings.Split(boardString, "/")
-
-    for j := 7; j >= 0; j-- {
-    	row := rows[7 - j]
-    	for i, notation := range strings.Split(row, "") {
-			piece := GetPieceByNotation(notation)
-    		board.PlacePieceOnBoard(i, j, piece)
-		}
+	board, err := ParseFEN(fen)
+	if err != nil {
+		panic(err)
 	}
 
-	board.whitesTurn = currentSide == "w"
-
-	for _, c := range strings.Split(castleRights, "") {
-		switch c {
-		case "K":
-			board.whiteCastleKing = true
-		case "Q":
-			board.whiteCastleQueen = true
-		case "k":
-			board.blackCastleKing = true
-		case "q":
-			board.blackCastleQueen = true
-		}
-	}
-
-	if enPassant == "-" {
-		board.enPassant = []int{-1, -1}
-	} else {
-		board.enPassant = AlgebraToRowCol(enPassant)
-	}
-
-	board.halfmove = halfmove
-	board.turn = turn
-
 	return board
 }
 
 func And(a, b BitBoard) BitBoard {
 	result := CreateEmptyBitBoard()
-	for i, row := range result.board {
-		for j, field := range row {
-			for k := range field {
-				result.board[i][j][k] = a.board[i][j][k] && b.board[i][j][k]
-			}
-		}
+	for k := range result.pieces {
+		result.pieces[k] = a.pieces[k] & b.pieces[k]
 	}
+	result.updateOccupancy()
+	result.hash = result.computeHash()
 
 	return result
 }
 
 func Or(a, b BitBoard) BitBoard {
 	result := CreateEmptyBitBoard()
-
-	for i, row := range result.board {
-		for j, field := range row {
-			for k := range field {
-				result.board[i][j][k] = a.board[i][j][k] || b.board[i][j][k]
-			}
-		}
+	for k := range result.pieces {
+		result.pieces[k] = a.pieces[k] | b.pieces[k]
 	}
+	result.updateOccupancy()
+	result.hash = result.computeHash()
 
 	return result
 }
 
 func Not(a BitBoard) BitBoard {
 	result := CreateEmptyBitBoard()
-
-	for i, row := range result.board {
-		for j, field := range row {
-			for k := range field {
-				result.board[i][j][k] = !a.board[i][j][k]
-			}
-		}
+	for k := range result.pieces {
+		result.pieces[k] = ^a.pieces[k]
 	}
+	result.updateOccupancy()
+	result.hash = result.computeHash()
 
 	return result
 }
 
 func (board BitBoard) Equal(other BitBoard) bool {
-	for i, row := range board.board {
-		for j, field := range row {
-			for k, value := range field {
-				if value != other.board[i][j][k] {
-					return false
-				}
-			}
-		}
+	if board.pieces != other.pieces {
+		return false
 	}
 
 	if board.blackCastleQueen != other.blackCastleQueen || board.blackCastleKing != other.blackCastleKing ||
 		board.whiteCastleQueen != other.whiteCastleQueen || board.whiteCastleKing != other.whiteCastleKing ||
 		board.whitesTurn != other.whitesTurn || board.turn != other.turn || board.halfmove != other.halfmove ||
-		board.enPassant[0] != other.enPassant[0] || board.enPassant[1] != other.enPassant[1] {
+		board.enPassant != other.enPassant {
 		return false
 	}
 
 	return true
 }
 
+// SamePosition ignores the move counters, as needed when looking for repetitions. The en passant field only counts
+// if the capture can be played.
+func (board BitBoard) SamePosition(other BitBoard) bool {
+	return board.hash == other.hash && board.pieces == other.pieces && board.whitesTurn == other.whitesTurn &&
+		board.blackCastleQueen == other.blackCastleQueen && board.blackCastleKing == other.blackCastleKing &&
+		board.whiteCastleQueen == other.whiteCastleQueen && board.whiteCastleKing == other.whiteCastleKing &&
+		board.enPassantTarget() == other.enPassantTarget()
+}
+
 func (board BitBoard) IsWhitesTurn() bool {
 	return board.whitesTurn
 }
 
 func (board *BitBoard) String() string {
 	output := ""
-	for i, row := range board.board {
-		for j := range row {
+	for i := 0; i < 8; i++ {
+		for j := 0; j < 8; j++ {
 			piece := board.GetPieceOnField(i, j)
 			output += " " + piece.GetNotation()
 		}
@@ -252,40 +235,19 @@ func (board *BitBoard) String() string {
 }
 
 func (board *BitBoard) isFieldEmpty(x, y int) bool {
-	for _, value := range board.board[x][y] {
-		if value {
-			return false
-		}
-	}
-	return true
+	return board.occupied&squareMask(x, y) == 0
 }
 
 func (board *BitBoard) isFieldBlack(x, y int) bool {
-	if board.isFieldEmpty(x, y) {
-		return false
-	}
-
-	for i := 0; i < 6; i++ {
-		if board.board[x][y][i] {
-			return true
-		}
-	}
-
-	return false
+	return board.black&squareMask(x, y) != 0
 }
 
 func (board *BitBoard) isFieldWhite(x, y int) bool {
-	if board.isFieldEmpty(x, y) {
-		return false
-	}
-
-	for i := 6; i < 12; i++ {
-		if board.board[x][y][i] {
-			return true
-		}
-	}
+	return board.white&squareMask(x, y) != 0
+}
 
-	return false
+func (board *BitBoard) isPieceOnField(x, y int, piece Piece) bool {
+	return board.pieces[piece]&squareMask(x, y) != 0
 }
 
 func (board *BitBoard) isFieldAvailable(x, y int, white bool) bool {
@@ -300,13 +262,27 @@ func (board *BitBoard) isFieldAvailable(x, y int, white bool) bool {
 }
 
 func (board *BitBoard) PlacePieceOnBoard(x, y int, piece Piece) {
-	for i := range board.board[x][y] {
-		board.board[x][y][i] = false
+	mask := squareMask(x, y)
+	for i := range board.pieces {
+		if board.pieces[i]&mask != 0 {
+			board.hash ^= pieceKeys[i][y*8+x]
+			board.pieces[i] &^= mask
+		}
 	}
+	board.white &^= mask
+	board.black &^= mask
+	board.occupied &^= mask
 	if piece == NO_PIECE {
 		return
 	}
-	board.board[x][y][piece] = true
+	board.pieces[piece] |= mask
+	board.hash ^= pieceKeys[piece][y*8+x]
+	board.occupied |= mask
+	if piece.IsWhite() {
+		board.white |= mask
+	} else {
+		board.black |= mask
+	}
 }
 
 func (board *BitBoard) GetPieceOnField(x, y int) Piece {
@@ -314,8 +290,9 @@ func (board *BitBoard) GetPieceOnField(x, y int) Piece {
 		return NO_PIECE
 	}
 
-	for i, piece := range board.board[x][y] {
-		if piece {
+	mask := squareMask(x, y)
+	for i, pieces := range board.pieces {
+		if pieces&mask != 0 {
 			return Piece(i)
 		}
 	}
@@ -323,6 +300,15 @@ func (board *BitBoard) GetPieceOnField(x, y int) Piece {
 	return NO_PIECE
 }
 
+// GetPieces returns the set of fields holding piece, bit y * 8 + x stands for the field (x, y)
+func (board *BitBoard) GetPieces(piece Piece) uint64 {
+	return board.pieces[piece]
+}
+
+func (board *BitBoard) CountPieces(piece Piece) int {
+	return bits.OnesCount64(board.pieces[piece])
+}
+
 func (board *BitBoard) findKing(white bool) (int, int) {
 	var piece Piece
 	if white {
@@ -331,66 +317,41 @@ func (board *BitBoard) findKing(white bool) (int, int) {
 		piece = BLACK_KING
 	}
 
-	for i, row := range board.board {
-		for j := range row {
-			if board.GetPieceOnField(i, j) == piece {
-				return i, j
-			}
-		}
+	if board.pieces[piece] == 0 {
+		return -1, -1
 	}
+	square := bits.TrailingZeros64(board.pieces[piece])
 
-	return -1, -1
+	return square % 8, square / 8
 }
 
 func (board *BitBoard) IsCheck(white bool) bool {
-	checkMatrix := CreateEmptyBitBoard()
 	x, y := board.findKing(white)
 
 	if x == -1 || y == -1 {
 		return false
 	}
-	for i := 0; i < 12; i++ {
-		checkMatrix.board[x][y][i] = true
-	}
 
-	for i, row := range board.board {
-		for j := range row {
-			piece := board.GetPieceOnField(i, j)
-			if piece == NO_PIECE {
-				continue
-			}
-
-			if (white && piece.IsWhite()) || (!white && piece.IsBlack()) {
-				continue
-			}
-
-			movementMatrix := piece.GetMovementMatrix(board, i, j, true)
-			combinedMatrix := And(checkMatrix, movementMatrix)
+	return board.isFieldAttacked(x, y, white)
+}
 
-			if !combinedMatrix.isFieldEmpty(x, y) {
-				return true
-			}
-		}
-	}
-	return false
+// checks whether the opponent of white could capture a piece of white standing on (x, y)
+func (board *BitBoard) isFieldAttacked(x, y int, white bool) bool {
+	return board.AttackersTo(y*8+x, !white) != 0
 }
 
 func (board *BitBoard) Copy() BitBoard {
-	copy := CreateEmptyBitBoard()
-
-	for i, row := range board.board {
-		for j := range row {
-			copy.PlacePieceOnBoard(i, j, board.GetPieceOnField(i, j))
-		}
-	}
-
-	return copy
+	return *board
 }
 
 func (board *BitBoard) doesMoveResultInCheck(x1, y1, x2, y2 int, white bool) bool {
 	movedBoard := board.Copy()
 
 	piece := movedBoard.GetPieceOnField(x1, y1)
+	if (piece == WHITE_PAWN || piece == BLACK_PAWN) && x1 != x2 && movedBoard.isFieldEmpty(x2, y2) {
+		// en passant, the captured pawn leaves its rank as well
+		movedBoard.PlacePieceOnBoard(x2, y1, NO_PIECE)
+	}
 	movedBoard.PlacePieceOnBoard(x1, y1, NO_PIECE)
 	movedBoard.PlacePieceOnBoard(x2, y2, piece)
 
@@ -401,17 +362,13 @@ func (board *BitBoard) IsCheckMate(white bool) bool {
 	if !board.IsCheck(white) {
 		return false
 	}
-	for i, row := range board.board {
-		for j := range row {
+	for i := 0; i < 8; i++ {
+		for j := 0; j < 8; j++ {
 			if (white && board.isFieldWhite(i, j)) || (!white && board.isFieldBlack(i, j)) {
 				piece := board.GetPieceOnField(i, j)
 				movementMatrix := piece.GetMovementMatrix(board, i, j, false)
-				for k, rowMovement := range movementMatrix.board {
-					for l := range rowMovement {
-						if !movementMatrix.isFieldEmpty(k, l) {
-							return false
-						}
-					}
+				if movementMatrix.occupied != 0 {
+					return false
 				}
 			}
 		}
@@ -419,6 +376,46 @@ func (board *BitBoard) IsCheckMate(white bool) bool {
 	return true
 }
 
+func (board *BitBoard) IsStalemate(white bool) bool {
+	if board.IsCheck(white) {
+		return false
+	}
+
+	own := board.black
+	if white {
+		own = board.white
+	}
+
+	for ; own != 0; own &= own - 1 {
+		square := bits.TrailingZeros64(own)
+		i, j := square%8, square/8
+		movementMatrix := board.GetPieceOnField(i, j).GetMovementMatrix(board, i, j, false)
+		if movementMatrix.occupied != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+const darkSquares uint64 = 0xAA55AA55AA55AA55
+
+// true if neither side can possibly checkmate: bare kings, a single minor piece, or only bishops on one square color
+func (board *BitBoard) IsInsufficientMaterial() bool {
+	heavy := board.pieces[WHITE_PAWN] | board.pieces[BLACK_PAWN] | board.pieces[WHITE_ROOK] |
+		board.pieces[BLACK_ROOK] | board.pieces[WHITE_QUEEN] | board.pieces[BLACK_QUEEN]
+	if heavy != 0 {
+		return false
+	}
+
+	knights := board.pieces[WHITE_KNIGHT] | board.pieces[BLACK_KNIGHT]
+	bishops := board.pieces[WHITE_BISHOP] | board.pieces[BLACK_BISHOP]
+	if bits.OnesCount64(knights|bishops) <= 1 {
+		return true
+	}
+
+	return knights == 0 && (bishops&darkSquares == 0 || bishops&^darkSquares == 0)
+}
+
 func (board *BitBoard) IsMoveValid(x1, y1, x2, y2 int) bool {
 	piece := board.GetPieceOnField(x1, y1)
 
@@ -483,9 +480,11 @@ func (board *BitBoard) GetCastleRightsKingSideAfterPieceMove(x, y int) bool {
 }
 
 func (board *BitBoard) SetEnPassant(x, y int) {
-	board.enPassant = []int{x, y}
+	board.hash ^= board.enPassantHash()
+	board.enPassant = [2]int{x, y}
+	board.hash ^= board.enPassantHash()
 }
 
 func (board *BitBoard) GetEnPassant() []int {
-	return board.enPassant
+	return []int{board.enPassant[0], board.enPassant[1]}
 }
\ No newline at end of file
@@ -4,25 +4,14 @@ import "testing"
 
 func TestCreateEmptyBitBoard(t *testing.T) {
 	bitBoard := CreateEmptyBitBoard()
-	board := bitBoard.board
-	if len(board) != 8 {
-		t.Error("Incorrect dimensions of board")
-	}
-	for _, row := range board {
-		if len(row) != 8 {
-			t.Error("Incorrect dimensions of board")
-		}
-		for _, field := range row {
-			if len(field) != 12 {
-				t.Error("Incorrect dimensions of board")
-			}
-			for _, value := range field {
-				if value {
-					t.Error("Board not empty")
-				}
-			}
+	for _, pieces := range bitBoard.pieces {
+		if pieces != 0 {
+			t.Error("Board not empty")
 		}
 	}
+	if bitBoard.white != 0 || bitBoard.black != 0 || bitBoard.occupied != 0 {
+		t.Error("Board not empty")
+	}
 
 	if !bitBoard.blackCastleQueen || !bitBoard.blackCastleKing ||
 		!bitBoard.whiteCastleQueen || !bitBoard.whiteCastleKing {
@@ -34,12 +23,12 @@ func TestBoardAnd(t *testing.T) {
 	board1 := CreateEmptyBitBoard()
 	board2 := CreateEmptyBitBoard()
 
-	board1.board[0][0][0] = true
-	board2.board[0][0][0] = true
+	board1.pieces[0] |= squareMask(0, 0)
+	board2.pieces[0] |= squareMask(0, 0)
 
 
 	andBoard := And(board1, board2)
-	if !andBoard.board[0][0][0] {
+	if !andBoard.isPieceOnField(0, 0, 0) {
 		t.Error("AND failed")
 	}
 }
@@ -47,10 +36,10 @@ func TestBoardAnd(t *testing.T) {
 func TestBoardNot(t *testing.T) {
 	board1 := CreateEmptyBitBoard()
 
-	board1.board[0][0][0] = true
+	board1.pieces[0] |= squareMask(0, 0)
 
 	notBoard := Not(board1)
-	if notBoard.board[0][0][0] || !notBoard.board[0][0][1] {
+	if notBoard.isPieceOnField(0, 0, 0) || !notBoard.isPieceOnField(0, 0, 1) {
 		t.Error("NOT failed")
 	}
 }
@@ -59,17 +48,14 @@ func TestBoardOr(t *testing.T) {
 	board1 := CreateEmptyBitBoard()
 	board2 := CreateEmptyBitBoard()
 
-	board1.board[0][0][0] = true
-	board2.board[0][0][0] = false
+	board1.pieces[0] |= squareMask(0, 0)
 
-	board1.board[0][0][1] = true
-	board2.board[0][0][1] = true
+	board1.pieces[1] |= squareMask(0, 0)
+	board2.pieces[1] |= squareMask(0, 0)
 
-	board1.board[0][0][2] = false
-	board2.board[0][0][2] = false
 
 	orBoard := Or(board1, board2)
-	if !orBoard.board[0][0][0] || !orBoard.board[0][0][1] || orBoard.board[0][0][2] {
+	if !orBoard.isPieceOnField(0, 0, 0) || !orBoard.isPieceOnField(0, 0, 1) || orBoard.isPieceOnField(0, 0, 2) {
 		t.Error("OR failed")
 	}
 }
@@ -78,16 +64,29 @@ func TestPlacePieceOnBoard(t *testing.T) {
 	board := CreateEmptyBitBoard()
 
 	board.PlacePieceOnBoard(0, 0, BLACK_QUEEN)
-	if !board.board[0][0][BLACK_QUEEN] {
+	if !board.isPieceOnField(0, 0, BLACK_QUEEN) {
 		t.Error("Piece hasn't been placed")
 	}
+
+	board.PlacePieceOnBoard(0, 0, WHITE_ROOK)
+	if board.isPieceOnField(0, 0, BLACK_QUEEN) || !board.isPieceOnField(0, 0, WHITE_ROOK) {
+		t.Error("Piece hasn't been replaced")
+	}
+	if board.black != 0 || board.white != squareMask(0, 0) || board.occupied != squareMask(0, 0) {
+		t.Error("occupancy not updated")
+	}
+
+	board.PlacePieceOnBoard(0, 0, NO_PIECE)
+	if board.occupied != 0 {
+		t.Error("Piece hasn't been removed")
+	}
 }
 
 func TestBitBoard_IsFieldEmpty(t *testing.T) {
 	board := CreateEmptyBitBoard()
 
-	for i, row := range board.board {
-		for j := range row {
+	for i := 0; i < 8; i++ {
+		for j := 0; j < 8; j++ {
 			if !board.isFieldEmpty(i, j) {
 				t.Error("not all fields are empty")
 			}
@@ -180,13 +179,18 @@ func TestBitBoard_Copy(t *testing.T) {
 	board := GetStartBoard()
 	copiedBoard := board.Copy()
 
-	for i, row := range board.board {
-		for j := range row {
+	for i := 0; i < 8; i++ {
+		for j := 0; j < 8; j++ {
 			if board.GetPieceOnField(i, j) != copiedBoard.GetPieceOnField(i, j) {
 				t.Error("invalid copy")
 			}
 		}
 	}
+
+	copiedBoard.PlacePieceOnBoard(4, 1, NO_PIECE)
+	if board.isFieldEmpty(4, 1) {
+		t.Error("changing the copy changed the original board")
+	}
 }
 
 /**
@@ -290,4 +294,95 @@ func TestBitBoard_IsMoveValid(t *testing.T) {
 	}
 
 
-}
\ No newline at end of file
+}
+func TestBitBoard_FromFEN(t *testing.T) {
+	fens := []string{
+		"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+		"8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1",
+		"rnbqkbnr/pp1ppppp/8/2p5/4P3/8/PPPP1PPP/RNBQKBNR w KQkq c6 0 2",
+		"4k3/8/8/8/8/8/8/4K3 b - - 12 40",
+	}
+
+	for _, fen := range fens {
+		board := FromFEN(fen)
+		if board.ToFEN() != fen {
+			t.Errorf("fen doesn't survive a round trip:\n%s\n%s", fen, board.ToFEN())
+		}
+	}
+
+	board := FromFEN("4k3/8/8/8/8/8/8/R3K3 w - - 0 1")
+	if board.GetPieceOnField(0, 0) != WHITE_ROOK || board.GetPieceOnField(4, 0) != WHITE_KING {
+		t.Error("empty fields in fen not skipped")
+	}
+	if board.whiteCastleKing || board.whiteCastleQueen || board.blackCastleKing || board.blackCastleQueen {
+		t.Error("castle rights should be empty")
+	}
+}
+
+func TestBitBoard_IsStalemate(t *testing.T) {
+	board := FromFEN("7k/5Q2/6K1/8/8/8/8/8 b - - 0 1")
+	if !board.IsStalemate(false) {
+		t.Error("black has no moves and is not in check")
+	}
+	if board.IsStalemate(true) {
+		t.Error("white can move")
+	}
+
+	board = FromFEN("7k/6Q1/6K1/8/8/8/8/8 b - - 0 1")
+	if board.IsStalemate(false) {
+		t.Error("check mate is no stalemate")
+	}
+}
+
+func TestBitBoard_IsInsufficientMaterial(t *testing.T) {
+	insufficient := []string{
+		"4k3/8/8/8/8/8/8/4K3 w - - 0 1",
+		"4k3/8/8/8/8/8/8/4KN2 w - - 0 1",
+		"4kb2/8/8/8/8/8/8/4K3 w - - 0 1",
+		"4k3/8/8/8/8/8/8/B1B1K3 w - - 0 1",
+		"4kb2/8/8/8/8/8/8/2B1K3 w - - 0 1",
+	}
+	for _, fen := range insufficient {
+		board := FromFEN(fen)
+		if !board.IsInsufficientMaterial() {
+			t.Errorf("material should be insufficient in %s", fen)
+		}
+	}
+
+	sufficient := []string{
+		"4k3/8/8/8/8/8/4P3/4K3 w - - 0 1",
+		"4k3/8/8/8/8/8/8/3RK3 w - - 0 1",
+		"4k3/8/8/8/8/8/8/2BBK3 w - - 0 1",
+		"4k3/8/8/8/8/8/8/3NKB2 w - - 0 1",
+		"4kn2/8/8/8/8/8/8/4KN2 w - - 0 1",
+	}
+	for _, fen := range sufficient {
+		board := FromFEN(fen)
+		if board.IsInsufficientMaterial() {
+			t.Errorf("material should be sufficient in %s", fen)
+		}
+	}
+}
+
+func TestBitBoard_SamePosition(t *testing.T) {
+	b1 := FromFEN("4k3/8/8/8/8/8/8/4K3 w - - 0 1")
+	b2 := FromFEN("4k3/8/8/8/8/8/8/4K3 w - - 8 5")
+	if !b1.SamePosition(b2) {
+		t.Error("move counters should be ignored")
+	}
+
+	b2.SetWhitesTurn(false)
+	if b1.SamePosition(b2) {
+		t.Error("side to move differs")
+	}
+}
+
+func TestBitBoard_CountPieces(t *testing.T) {
+	board := GetStartBoard()
+	if board.CountPieces(WHITE_PAWN) != 8 || board.CountPieces(BLACK_KNIGHT) != 2 || board.CountPieces(WHITE_KING) != 1 {
+		t.Error("wrong piece count in start position")
+	}
+	if board.GetPieces(WHITE_KING) != squareMask(4, 0) || board.GetPieces(BLACK_ROOK) != squareMask(0, 7)|squareMask(7, 7) {
+		t.Error("wrong piece sets in start position")
+	}
+}
@@ -1,8 +1,8 @@
 package board
 
 import (
+	"fmt"
 	"strconv"
-	"strings"
 )
 
 func RowColToAlgebra(row, col int) string {
@@ -12,12 +12,21 @@ func RowColToAlgebra(row, col int) string {
 	return string(rowChar) + strconv.Itoa(col)
 }
 
+// AlgebraToRowCol returns -1, -1 for anything that isn't a square, use ParseSquare to get an error instead
 func AlgebraToRowCol(c string) []int {
-	cSplit := strings.Split(c, "")
-	rowChar := cSplit[0]
-	col, _ := strconv.Atoi(cSplit[1])
+	row, col, err := ParseSquare(c)
+	if err != nil {
+		return []int{-1, -1}
+	}
 
-	row := int([]rune(rowChar)[0] - 'a')
+	return []int{row, col}
+}
+
+// ParseSquare is the checked counterpart of AlgebraToRowCol
+func ParseSquare(c string) (int, int, error) {
+	if len(c) != 2 || c[0] < 'a' || c[0] > 'h' || c[1] < '1' || c[1] > '8' {
+		return -1, -1, fmt.Errorf("invalid square %q", c)
+	}
 
-	return []int{row, col - 1}
+	return int(c[0] - 'a'), int(c[1] - '1'), nil
 }
@@ -23,4 +23,23 @@ func TestAlgebraToRowCol(t *testing.T) {
 			t.Errorf("invalid conversion of h8: %d\n", i)
 		}
 	}
+
+	for _, c := range []string{"", "e", "z9"} {
+		if i := AlgebraToRowCol(c); i[0] != -1 || i[1] != -1 {
+			t.Errorf("invalid square %q should give -1, -1, got %v", c, i)
+		}
+	}
+}
+
+func TestParseSquare(t *testing.T) {
+	x, y, err := ParseSquare("e4")
+	if err != nil || x != 4 || y != 3 {
+		t.Errorf("invalid conversion of e4: %d %d %v", x, y, err)
+	}
+
+	for _, c := range []string{"", "e", "e9", "i1", "e44", "4e", "E4"} {
+		if _, _, err := ParseSquare(c); err == nil {
+			t.Errorf("%q should not be a valid square", c)
+		}
+	}
 }
@@ -0,0 +1,171 @@
+package board
+
+import (
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+)
+
+// ParseFEN reads all six fields of a FEN and rejects positions that can't occur in a game
+func ParseFEN(fen string) (BitBoard, error) {
+	board := CreateEmptyBitBoard()
+
+	fields := strings.Fields(fen)
+	if len(fields) != 6 {
+		return board, fmt.Errorf("fen needs 6 fields, got %d in %q", len(fields), fen)
+	}
+
+	if err := board.parsePlacement(fields[0]); err != nil {
+		return board, err
+	}
+
+	switch fields[1] {
+	case "w":
+		board.whitesTurn = true
+	case "b":
+		board.whitesTurn = false
+	default:
+		return board, fmt.Errorf("side to move must be w or b, got %q", fields[1])
+	}
+
+	if err := board.parseCastleRights(fields[2]); err != nil {
+		return board, err
+	}
+
+	if err := board.parseEnPassant(fields[3]); err != nil {
+		return board, err
+	}
+
+	halfmove, err := strconv.Atoi(fields[4])
+	if err != nil || halfmove < 0 {
+		return board, fmt.Errorf("halfmove clock must be a non-negative number, got %q", fields[4])
+	}
+	board.halfmove = halfmove
+
+	turn, err := strconv.Atoi(fields[5])
+	if err != nil || turn < 1 {
+		return board, fmt.Errorf("turn must be a positive number, got %q", fields[5])
+	}
+	board.turn = turn
+
+	if board.IsCheck(!board.whitesTurn) {
+		return board, fmt.Errorf("side not to move is in check")
+	}
+
+	board.hash = board.computeHash()
+
+	return board, nil
+}
+
+func (board *BitBoard) parsePlacement(placement string) error {
+	rows := strings.Split(placement, "/")
+	if len(rows) != 8 {
+		return fmt.Errorf("piece placement needs 8 ranks, got %d", len(rows))
+	}
+
+	for j := 7; j >= 0; j-- {
+		row := rows[7-j]
+		i := 0
+		lastWasDigit := false
+		for _, c := range row {
+			if c >= '1' && c <= '8' {
+				if lastWasDigit {
+					return fmt.Errorf("rank %d has two consecutive digits", j+1)
+				}
+				i += int(c - '0')
+				lastWasDigit = true
+				continue
+			}
+			lastWasDigit = false
+
+			piece := GetPieceByNotation(string(c))
+			if piece == NO_PIECE {
+				return fmt.Errorf("invalid piece %q on rank %d", c, j+1)
+			}
+			if i >= 8 {
+				return fmt.Errorf("rank %d has more than 8 fields", j+1)
+			}
+			if (piece == WHITE_PAWN || piece == BLACK_PAWN) && (j == 0 || j == 7) {
+				return fmt.Errorf("pawn on rank %d", j+1)
+			}
+			board.PlacePieceOnBoard(i, j, piece)
+			i++
+		}
+
+		if i != 8 {
+			return fmt.Errorf("rank %d has %d fields instead of 8", j+1, i)
+		}
+	}
+
+	for _, king := range []Piece{WHITE_KING, BLACK_KING} {
+		if count := bits.OnesCount64(board.pieces[king]); count != 1 {
+			return fmt.Errorf("expected exactly one %s, got %d", king.GetNotation(), count)
+		}
+	}
+
+	return nil
+}
+
+func (board *BitBoard) parseCastleRights(castleRights string) error {
+	board.whiteCastleKing = false
+	board.whiteCastleQueen = false
+	board.blackCastleKing = false
+	board.blackCastleQueen = false
+
+	if castleRights == "-" {
+		return nil
+	}
+
+	for _, c := range castleRights {
+		var right *bool
+		var king, rook Piece
+		var row, column int
+		switch c {
+		case 'K':
+			right, king, rook, row, column = &board.whiteCastleKing, WHITE_KING, WHITE_ROOK, 0, 7
+		case 'Q':
+			right, king, rook, row, column = &board.whiteCastleQueen, WHITE_KING, WHITE_ROOK, 0, 0
+		case 'k':
+			right, king, rook, row, column = &board.blackCastleKing, BLACK_KING, BLACK_ROOK, 7, 7
+		case 'q':
+			right, king, rook, row, column = &board.blackCastleQueen, BLACK_KING, BLACK_ROOK, 7, 0
+		default:
+			return fmt.Errorf("invalid castle right %q", c)
+		}
+
+		if *right {
+			return fmt.Errorf("castle right %q given twice", c)
+		}
+		if !board.isPieceOnField(4, row, king) || !board.isPieceOnField(column, row, rook) {
+			return fmt.Errorf("castle right %q without king and rook on their starting fields", c)
+		}
+		*right = true
+	}
+
+	return nil
+}
+
+func (board *BitBoard) parseEnPassant(enPassant string) error {
+	if enPassant == "-" {
+		board.enPassant = [2]int{-1, -1}
+		return nil
+	}
+
+	x, y, err := ParseSquare(enPassant)
+	if err != nil {
+		return fmt.Errorf("invalid en passant field: %v", err)
+	}
+
+	// the pawn that just moved two fields stands in front of the en passant field
+	if board.whitesTurn && (y != 5 || !board.isPieceOnField(x, 4, BLACK_PAWN)) ||
+		!board.whitesTurn && (y != 2 || !board.isPieceOnField(x, 3, WHITE_PAWN)) {
+		return fmt.Errorf("no pawn can be captured en passant on %s", enPassant)
+	}
+	if !board.isFieldEmpty(x, y) {
+		return fmt.Errorf("en passant field %s is occupied", enPassant)
+	}
+
+	board.enPassant = [2]int{x, y}
+	return nil
+}
@@ -0,0 +1,61 @@
+package board
+
+import "testing"
+
+func TestParseFEN(t *testing.T) {
+	fen := "r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1"
+	board, err := ParseFEN(fen)
+	if err != nil {
+		t.Fatalf("valid fen rejected: %v", err)
+	}
+	if board.ToFEN() != fen {
+		t.Errorf("fen doesn't survive a round trip:\n%s", board.ToFEN())
+	}
+
+	board, err = ParseFEN("rnbqkbnr/ppp1pppp/8/8/3pP3/8/PPPP1PPP/RNBQKBNR b Qk e3 0 3")
+	if err != nil {
+		t.Fatalf("valid fen rejected: %v", err)
+	}
+	if board.GetEnPassant()[0] != 4 || board.GetEnPassant()[1] != 2 {
+		t.Error("en passant field not parsed")
+	}
+	if board.whiteCastleKing || !board.whiteCastleQueen || !board.blackCastleKing || board.blackCastleQueen {
+		t.Error("castle rights not parsed")
+	}
+}
+
+func TestParseFEN_Invalid(t *testing.T) {
+	invalid := map[string]string{
+		"empty":                   "",
+		"missing counters":        "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq -",
+		"7 ranks":                 "rnbqkbnr/pppppppp/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+		"short rank":              "rnbqkbnr/pppppppp/8/8/7/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+		"long rank":               "rnbqkbnr/ppppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+		"long rank with digits":   "rnbqkbnr/pppppppp/8/8/4P4/8/PPPP1PPP/RNBQKBNR w KQkq - 0 1",
+		"consecutive digits":      "rnbqkbnr/pppppppp/8/8/44/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+		"invalid piece":           "rnbqkbnr/pppppppp/8/8/3X4/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+		"zero":                    "rnbqkbnr/pppppppp/8/8/08/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+		"pawn on last rank":       "rnbqkbnP/pppppppp/8/8/8/8/PPPPPPP1/RNBQKBNR w KQq - 0 1",
+		"no black king":           "rnbqqbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQ - 0 1",
+		"two white kings":         "rnbqkbnr/pppppppp/8/8/3K4/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+		"side to move":            "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR x KQkq - 0 1",
+		"castle character":        "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkx - 0 1",
+		"castle twice":            "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KKkq - 0 1",
+		"castle without rook":     "rnbqkbn1/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+		"castle with moved king":  "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQ1BNR w KQkq - 0 1",
+		"en passant square":       "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq e9 0 1",
+		"en passant without pawn": "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq e6 0 1",
+		"en passant wrong rank":   "rnbqkbnr/ppp1pppp/8/8/3pP3/8/PPPP1PPP/RNBQKBNR b KQkq e4 0 3",
+		"halfmove clock":          "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - x 1",
+		"negative halfmove":       "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - -1 1",
+		"turn zero":               "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 0",
+		"opponent in check":       "4k3/8/8/8/8/8/8/4R1K1 w - - 0 1",
+		"opponent in check black": "4k3/4r3/8/8/8/8/8/4K3 b - - 0 1",
+	}
+
+	for name, fen := range invalid {
+		if _, err := ParseFEN(fen); err == nil {
+			t.Errorf("%s: fen should be rejected: %q", name, fen)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package board
+
+import "math/bits"
+
+// attack tables for the pieces that don't slide, filled at init
+var knightTable [64]uint64
+var kingTable [64]uint64
+
+// indexed by 1 for white and 0 for black
+var pawnTable [2][64]uint64
+
+// magic maps the blockers of a slider on its relevant fields to an index into its attack table:
+// ((occupied & mask) * number) >> shift
+type magic struct {
+	mask    uint64
+	number  uint64
+	shift   uint
+	attacks []uint64
+}
+
+var rookMagics [64]magic
+var bishopMagics [64]magic
+
+// fields strictly between two fields on a common rank, file or diagonal and the whole line through both, 0 otherwise
+var betweenTable [64][64]uint64
+var lineTable [64][64]uint64
+
+func init() {
+	for square := 0; square < 64; square++ {
+		knightTable[square] = stepAttacks(square, knightJumps[:])
+		kingTable[square] = stepAttacks(square, kingSteps[:])
+		pawnTable[1][square] = stepAttacks(square, [][2]int{{-1, 1}, {1, 1}})
+		pawnTable[0][square] = stepAttacks(square, [][2]int{{-1, -1}, {1, -1}})
+	}
+
+	for square := 0; square < 64; square++ {
+		rookMagics[square] = findMagic(square, rookDirections)
+		bishopMagics[square] = findMagic(square, bishopDirections)
+	}
+
+	for a := 0; a < 64; a++ {
+		for b := 0; b < 64; b++ {
+			if a == b {
+				continue
+			}
+			ends := uint64(1)<<uint(a) | uint64(1)<<uint(b)
+			if rookAttacks(a, 0)&(1<<uint(b)) != 0 {
+				betweenTable[a][b] = rookAttacks(a, 1<<uint(b)) & rookAttacks(b, 1<<uint(a))
+				lineTable[a][b] = rookAttacks(a, 0)&rookAttacks(b, 0) | ends
+			}
+			if bishopAttacks(a, 0)&(1<<uint(b)) != 0 {
+				betweenTable[a][b] = bishopAttacks(a, 1<<uint(b)) & bishopAttacks(b, 1<<uint(a))
+				lineTable[a][b] = bishopAttacks(a, 0)&bishopAttacks(b, 0) | ends
+			}
+		}
+	}
+}
+
+// seeds per rank that lead to magic numbers quickly, taken from stockfish
+var magicSeeds = [8]uint64{728, 10316, 55013, 32803, 12281, 15100, 16645, 255}
+
+// xorshift64star random numbers, cheap and the same on every platform
+type magicRandom uint64
+
+func (r *magicRandom) next() uint64 {
+	*r ^= *r >> 12
+	*r ^= *r << 25
+	*r ^= *r >> 27
+	return uint64(*r) * 2685821657736338717
+}
+
+// sparse numbers with few bits set make better magic numbers
+func (r *magicRandom) sparse() uint64 {
+	return r.next() & r.next() & r.next()
+}
+
+// relevantMask are the fields whose occupancy changes the attacks from square, the last field of every ray always
+// stops the slider so it doesn't matter
+func relevantMask(square int, directions [4][2]int) uint64 {
+	x, y := square%8, square/8
+	var mask uint64
+	for _, direction := range directions {
+		for i, j := x+direction[0], y+direction[1]; onBoard(i+direction[0], j+direction[1]); i, j = i+direction[0], j+direction[1] {
+			mask |= squareMask(i, j)
+		}
+	}
+	return mask
+}
+
+// findMagic tries sparse random numbers until one maps all blocker sets without a harmful collision
+func findMagic(square int, directions [4][2]int) magic {
+	mask := relevantMask(square, directions)
+	count := bits.OnesCount64(mask)
+
+	// walk all subsets of the mask
+	size := 1 << uint(count)
+	occupancies := make([]uint64, 0, size)
+	references := make([]uint64, 0, size)
+	for subset := uint64(0); ; {
+		occupancies = append(occupancies, subset)
+		references = append(references, slidingAttacks(square, subset, directions))
+		subset = (subset - mask) & mask
+		if subset == 0 {
+			break
+		}
+	}
+
+	m := magic{mask: mask, shift: uint(64 - count), attacks: make([]uint64, size)}
+	used := make([]int, size)
+	random := magicRandom(magicSeeds[square/8])
+	for try := 1; ; try++ {
+		m.number = random.sparse()
+		if bits.OnesCount64((mask*m.number)>>56) < 6 {
+			continue
+		}
+
+		ok := true
+		for i, occupancy := range occupancies {
+			index := (occupancy * m.number) >> m.shift
+			if used[index] != try {
+				used[index] = try
+				m.attacks[index] = references[i]
+			} else if m.attacks[index] != references[i] {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return m
+		}
+	}
+}
+
+func (m *magic) lookup(occupied uint64) uint64 {
+	return m.attacks[((occupied&m.mask)*m.number)>>m.shift]
+}
@@ -0,0 +1,98 @@
+package board
+
+import (
+	"math/bits"
+	"math/rand"
+	"testing"
+)
+
+func TestMagicAttacks(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+
+	for square := 0; square < 64; square++ {
+		for i := 0; i < 1000; i++ {
+			// sparse and dense occupancies
+			occupied := random.Uint64() & random.Uint64()
+			if i%2 == 0 {
+				occupied = random.Uint64() | random.Uint64()
+			}
+
+			if rookAttacks(square, occupied) != walkRookRays(square%8, square/8, occupied) {
+				t.Fatalf("wrong rook attacks from %s with occupancy %#x", RowColToAlgebra(square%8, square/8), occupied)
+			}
+			if bishopAttacks(square, occupied) != walkBishopRays(square%8, square/8, occupied) {
+				t.Fatalf("wrong bishop attacks from %s with occupancy %#x", RowColToAlgebra(square%8, square/8), occupied)
+			}
+		}
+	}
+}
+
+// walkRookRays and walkBishopRays are the ray walks of the old getRookMatrix and getBishopMatrix, kept apart from
+// slidingAttacks so the magic tables are checked against code that didn't fill them. The first blocker is attacked
+// whatever its color.
+func walkRookRays(x, y int, occupied uint64) uint64 {
+	var attacks uint64
+	add := func(i, j int) bool {
+		attacks |= uint64(1) << uint(j*8+i)
+		return occupied&(uint64(1)<<uint(j*8+i)) == 0
+	}
+
+	for i := x + 1; i < 8 && add(i, y); i++ {
+	}
+	for i := x - 1; i >= 0 && add(i, y); i-- {
+	}
+	for j := y + 1; j < 8 && add(x, j); j++ {
+	}
+	for j := y - 1; j >= 0 && add(x, j); j-- {
+	}
+
+	return attacks
+}
+
+func walkBishopRays(x, y int, occupied uint64) uint64 {
+	var attacks uint64
+	add := func(i, j int) bool {
+		attacks |= uint64(1) << uint(j*8+i)
+		return occupied&(uint64(1)<<uint(j*8+i)) == 0
+	}
+	// the diagonals are the lines j = b1 + i and j = b2 - i
+	b1 := y - x
+	b2 := y + x
+
+	for i := x + 1; i < 8 && b1+i < 8 && add(i, b1+i); i++ {
+	}
+	for i := x + 1; i < 8 && b2-i >= 0 && add(i, b2-i); i++ {
+	}
+	for i := x - 1; i >= 0 && b1+i >= 0 && add(i, b1+i); i-- {
+	}
+	for i := x - 1; i >= 0 && b2-i < 8 && add(i, b2-i); i-- {
+	}
+
+	return attacks
+}
+
+func TestAttackTables(t *testing.T) {
+	// a1, d4 and h8
+	if knightAttacks(0) != squareMask(1, 2)|squareMask(2, 1) || bits.OnesCount64(knightAttacks(27)) != 8 || bits.OnesCount64(knightAttacks(63)) != 2 {
+		t.Error("wrong knight attacks")
+	}
+	if kingAttacks(0) != squareMask(1, 0)|squareMask(0, 1)|squareMask(1, 1) || bits.OnesCount64(kingAttacks(27)) != 8 {
+		t.Error("wrong king attacks")
+	}
+	if pawnAttacks(27, true) != squareMask(2, 4)|squareMask(4, 4) || pawnAttacks(27, false) != squareMask(2, 2)|squareMask(4, 2) {
+		t.Error("wrong pawn attacks")
+	}
+	if pawnAttacks(8, true) != squareMask(1, 2) || pawnAttacks(63, true) != 0 {
+		t.Error("pawn attacks should stay on the board")
+	}
+}
+
+func TestRelevantMask(t *testing.T) {
+	// a rook on a1 sees b1 to g1 and a2 to a7, the board edge doesn't matter
+	if mask := relevantMask(0, rookDirections); mask != 0x000101010101017E {
+		t.Errorf("wrong rook mask %#x", mask)
+	}
+	if mask := relevantMask(27, bishopDirections); bits.OnesCount64(mask) != 9 {
+		t.Errorf("wrong bishop mask %#x", mask)
+	}
+}
@@ -0,0 +1,280 @@
+package board
+
+import (
+	"fmt"
+	"strings"
+)
+
+type MoveFlag int
+
+const (
+	CAPTURE     MoveFlag = 1
+	CASTLE      MoveFlag = 2
+	EN_PASSANT  MoveFlag = 4
+	DOUBLE_PUSH MoveFlag = 8
+)
+
+type Move struct {
+	FromX     int
+	FromY     int
+	ToX       int
+	ToY       int
+	Promotion Piece
+	Flags     MoveFlag
+}
+
+func NewMove(fromX, fromY, toX, toY int) Move {
+	return Move{fromX, fromY, toX, toY, NO_PIECE, 0}
+}
+
+func (move Move) IsCapture() bool {
+	return move.Flags&CAPTURE != 0
+}
+
+func (move Move) IsCastle() bool {
+	return move.Flags&CASTLE != 0
+}
+
+func (move Move) IsEnPassant() bool {
+	return move.Flags&EN_PASSANT != 0
+}
+
+func (move Move) IsDoublePush() bool {
+	return move.Flags&DOUBLE_PUSH != 0
+}
+
+func (move Move) IsPromotion() bool {
+	return move.Promotion != NO_PIECE
+}
+
+// UCI writes the move in long algebraic notation as used by UCI, e.g. e2e4, e7e8q and e1g1 for castling
+func (move Move) UCI() string {
+	output := RowColToAlgebra(move.FromX, move.FromY) + RowColToAlgebra(move.ToX, move.ToY)
+	if move.IsPromotion() {
+		output += strings.ToLower(move.Promotion.GetNotation())
+	}
+	return output
+}
+
+func (move Move) String() string {
+	return move.UCI()
+}
+
+// ParseUCIMove finds the legal move of board written in long algebraic notation
+func ParseUCIMove(board *BitBoard, text string) (Move, error) {
+	if len(text) != 4 && len(text) != 5 {
+		return Move{}, fmt.Errorf("invalid move %q", text)
+	}
+
+	fromX, fromY, err := ParseSquare(text[0:2])
+	if err != nil {
+		return Move{}, fmt.Errorf("invalid move %q: %v", text, err)
+	}
+	toX, toY, err := ParseSquare(text[2:4])
+	if err != nil {
+		return Move{}, fmt.Errorf("invalid move %q: %v", text, err)
+	}
+
+	promotion := NO_PIECE
+	if len(text) == 5 {
+		if !strings.ContainsRune("qrbn", rune(text[4])) {
+			return Move{}, fmt.Errorf("invalid promotion in %q", text)
+		}
+		promotion = GetPieceByNotation(text[4:])
+	}
+
+	for _, move := range board.LegalMoves() {
+		if move.FromX != fromX || move.FromY != fromY || move.ToX != toX || move.ToY != toY {
+			continue
+		}
+		if promotion == NO_PIECE && !move.IsPromotion() || move.IsPromotion() && move.Promotion%6 == promotion%6 {
+			return move, nil
+		}
+	}
+
+	return Move{}, fmt.Errorf("illegal move %s", text)
+}
+
+func (board *BitBoard) LegalMoves() []Move {
+	return board.generateMoves(false)
+}
+
+// CaptureMoves returns the legal captures and promotions, the moves a quiescence search looks at
+func (board *BitBoard) CaptureMoves() []Move {
+	return board.generateMoves(true)
+}
+
+func appendPromotions(moves []Move, move Move, white bool) []Move {
+	promotions := []Piece{BLACK_QUEEN, BLACK_ROOK, BLACK_BISHOP, BLACK_KNIGHT}
+	if white {
+		promotions = []Piece{WHITE_QUEEN, WHITE_ROOK, WHITE_BISHOP, WHITE_KNIGHT}
+	}
+
+	for _, promotion := range promotions {
+		move.Promotion = promotion
+		moves = append(moves, move)
+	}
+	return moves
+}
+
+func (board *BitBoard) newMoveWithFlags(piece Piece, x1, y1, x2, y2 int) Move {
+	move := NewMove(x1, y1, x2, y2)
+
+	if !board.isFieldEmpty(x2, y2) {
+		move.Flags |= CAPTURE
+	}
+
+	if (piece == WHITE_KING || piece == BLACK_KING) && (x2-x1 == 2 || x1-x2 == 2) {
+		move.Flags |= CASTLE
+	}
+
+	if piece == WHITE_PAWN || piece == BLACK_PAWN {
+		if x1 != x2 && board.isFieldEmpty(x2, y2) && board.enPassant == [2]int{x2, y2} {
+			move.Flags |= CAPTURE | EN_PASSANT
+		}
+		if y2-y1 == 2 || y1-y2 == 2 {
+			move.Flags |= DOUBLE_PUSH
+		}
+	}
+
+	return move
+}
+
+// Undo holds everything MakeMove overwrites that can't be recovered from the move itself
+type Undo struct {
+	captured         Piece
+	blackCastleQueen bool
+	blackCastleKing  bool
+	whiteCastleQueen bool
+	whiteCastleKing  bool
+	whitesTurn       bool
+	turn             int
+	halfmove         int
+	enPassant        [2]int
+	hash             uint64
+}
+
+func (board *BitBoard) MakeMove(move Move) Undo {
+	undo := Undo{
+		captured:         board.GetPieceOnField(move.ToX, move.ToY),
+		blackCastleQueen: board.blackCastleQueen,
+		blackCastleKing:  board.blackCastleKing,
+		whiteCastleQueen: board.whiteCastleQueen,
+		whiteCastleKing:  board.whiteCastleKing,
+		whitesTurn:       board.whitesTurn,
+		turn:             board.turn,
+		halfmove:         board.halfmove,
+		enPassant:        board.enPassant,
+		hash:             board.hash,
+	}
+
+	piece := board.GetPieceOnField(move.FromX, move.FromY)
+
+	board.hash ^= board.enPassantHash()
+	board.hash ^= board.castleHash()
+	kingSide := board.GetCastleRightsKingSideAfterPieceMove(move.FromX, move.FromY)
+	queenSide := board.GetCastleRightsQueenSideAfterPieceMove(move.FromX, move.FromY)
+	if board.whitesTurn {
+		board.whiteCastleKing = kingSide
+		board.whiteCastleQueen = queenSide
+	} else {
+		board.blackCastleKing = kingSide
+		board.blackCastleQueen = queenSide
+	}
+
+	// a rook captured on its starting field takes the opponent's castle rights with it
+	switch {
+	case move.ToX == 0 && move.ToY == 0:
+		board.whiteCastleQueen = false
+	case move.ToX == 7 && move.ToY == 0:
+		board.whiteCastleKing = false
+	case move.ToX == 0 && move.ToY == 7:
+		board.blackCastleQueen = false
+	case move.ToX == 7 && move.ToY == 7:
+		board.blackCastleKing = false
+	}
+	board.hash ^= board.castleHash()
+
+	if move.IsEnPassant() {
+		undo.captured = board.GetPieceOnField(move.ToX, move.FromY)
+		board.PlacePieceOnBoard(move.ToX, move.FromY, NO_PIECE)
+	}
+
+	board.PlacePieceOnBoard(move.FromX, move.FromY, NO_PIECE)
+	if move.IsPromotion() {
+		board.PlacePieceOnBoard(move.ToX, move.ToY, move.Promotion)
+	} else {
+		board.PlacePieceOnBoard(move.ToX, move.ToY, piece)
+	}
+
+	if move.IsCastle() {
+		rookFromX, rookToX := castleRookFiles(move)
+		rook := board.GetPieceOnField(rookFromX, move.FromY)
+		board.PlacePieceOnBoard(rookFromX, move.FromY, NO_PIECE)
+		board.PlacePieceOnBoard(rookToX, move.FromY, rook)
+	}
+
+	if move.IsDoublePush() {
+		board.enPassant = [2]int{move.FromX, (move.FromY + move.ToY) / 2}
+	} else {
+		board.enPassant = [2]int{-1, -1}
+	}
+
+	if piece == WHITE_PAWN || piece == BLACK_PAWN || move.IsCapture() {
+		board.halfmove = 0
+	} else {
+		board.halfmove++
+	}
+
+	if !board.whitesTurn {
+		board.turn++
+	}
+	board.whitesTurn = !board.whitesTurn
+	board.hash ^= whitesTurnKey
+	board.hash ^= board.enPassantHash()
+
+	return undo
+}
+
+func (board *BitBoard) UnmakeMove(move Move, undo Undo) {
+	piece := board.GetPieceOnField(move.ToX, move.ToY)
+	if move.IsPromotion() {
+		if piece.IsWhite() {
+			piece = WHITE_PAWN
+		} else {
+			piece = BLACK_PAWN
+		}
+	}
+
+	board.PlacePieceOnBoard(move.FromX, move.FromY, piece)
+	if move.IsEnPassant() {
+		board.PlacePieceOnBoard(move.ToX, move.ToY, NO_PIECE)
+		board.PlacePieceOnBoard(move.ToX, move.FromY, undo.captured)
+	} else {
+		board.PlacePieceOnBoard(move.ToX, move.ToY, undo.captured)
+	}
+
+	if move.IsCastle() {
+		rookFromX, rookToX := castleRookFiles(move)
+		rook := board.GetPieceOnField(rookToX, move.FromY)
+		board.PlacePieceOnBoard(rookToX, move.FromY, NO_PIECE)
+		board.PlacePieceOnBoard(rookFromX, move.FromY, rook)
+	}
+
+	board.blackCastleQueen = undo.blackCastleQueen
+	board.blackCastleKing = undo.blackCastleKing
+	board.whiteCastleQueen = undo.whiteCastleQueen
+	board.whiteCastleKing = undo.whiteCastleKing
+	board.whitesTurn = undo.whitesTurn
+	board.turn = undo.turn
+	board.halfmove = undo.halfmove
+	board.enPassant = undo.enPassant
+	board.hash = undo.hash
+}
+
+func castleRookFiles(move Move) (int, int) {
+	if move.ToX > move.FromX {
+		return 7, 5
+	}
+	return 0, 3
+}
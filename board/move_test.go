@@ -0,0 +1,357 @@
+package board
+
+import "testing"
+
+func containsMove(moves []Move, move Move) bool {
+	for _, m := range moves {
+		if m == move {
+			return true
+		}
+	}
+	return false
+}
+
+func TestBitBoard_LegalMovesStartPosition(t *testing.T) {
+	board := GetStartBoard()
+	moves := board.LegalMoves()
+
+	if len(moves) != 20 {
+		t.Errorf("expected 20 moves in starting position, got %d", len(moves))
+	}
+
+	doublePushes := 0
+	for _, move := range moves {
+		if move.IsCapture() || move.IsCastle() || move.IsEnPassant() || move.IsPromotion() {
+			t.Errorf("invalid flags for move %s", move)
+		}
+		if move.IsDoublePush() {
+			doublePushes++
+		}
+	}
+	if doublePushes != 8 {
+		t.Errorf("expected 8 double pushes, got %d", doublePushes)
+	}
+
+	if !containsMove(moves, NewMove(6, 0, 5, 2)) {
+		t.Error("knight move g1f3 is missing")
+	}
+
+	board.SetWhitesTurn(false)
+	moves = board.LegalMoves()
+	if len(moves) != 20 {
+		t.Errorf("expected 20 moves for black in starting position, got %d", len(moves))
+	}
+	for _, move := range moves {
+		if !board.GetPieceOnField(move.FromX, move.FromY).IsBlack() {
+			t.Errorf("move %s doesn't move a black piece", move)
+		}
+	}
+}
+
+func TestBitBoard_LegalMovesFlags(t *testing.T) {
+	board := CreateEmptyBitBoard()
+	board.PlacePieceOnBoard(4, 0, WHITE_KING)
+	board.PlacePieceOnBoard(4, 7, BLACK_KING)
+	board.PlacePieceOnBoard(4, 4, WHITE_PAWN)
+	board.PlacePieceOnBoard(3, 4, BLACK_PAWN)
+	board.PlacePieceOnBoard(5, 5, BLACK_KNIGHT)
+	board.SetEnPassant(3, 5)
+
+	moves := board.LegalMoves()
+
+	enPassant := NewMove(4, 4, 3, 5)
+	enPassant.Flags = CAPTURE | EN_PASSANT
+	if !containsMove(moves, enPassant) {
+		t.Error("en passant capture is missing")
+	}
+
+	capture := NewMove(4, 4, 5, 5)
+	capture.Flags = CAPTURE
+	if !containsMove(moves, capture) {
+		t.Error("pawn capture is missing")
+	}
+
+	if !containsMove(moves, NewMove(4, 4, 4, 5)) {
+		t.Error("quiet pawn move is missing")
+	}
+}
+
+func TestBitBoard_LegalMovesInCheck(t *testing.T) {
+	board := CreateEmptyBitBoard()
+	board.PlacePieceOnBoard(0, 0, WHITE_KING)
+	board.PlacePieceOnBoard(7, 7, BLACK_KING)
+	board.PlacePieceOnBoard(0, 7, BLACK_ROOK)
+	board.PlacePieceOnBoard(1, 7, BLACK_ROOK)
+	board.PlacePieceOnBoard(3, 3, WHITE_BISHOP)
+
+	moves := board.LegalMoves()
+
+	if len(moves) != 1 {
+		t.Errorf("only the bishop block should be legal, got %v", moves)
+	}
+	if !containsMove(moves, NewMove(3, 3, 0, 6)) {
+		t.Error("blocking move is missing")
+	}
+}
+
+func TestBitBoard_MakeMove(t *testing.T) {
+	board := GetStartBoard()
+
+	moves := []Move{NewMove(4, 1, 4, 3), NewMove(4, 6, 4, 4), NewMove(6, 0, 5, 2), NewMove(4, 7, 4, 6)}
+	moves[0].Flags = DOUBLE_PUSH
+	moves[1].Flags = DOUBLE_PUSH
+	fens := []string{
+		"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1",
+		"rnbqkbnr/pppp1ppp/8/4p3/4P3/8/PPPP1PPP/RNBQKBNR w KQkq e6 0 2",
+		"rnbqkbnr/pppp1ppp/8/4p3/4P3/5N2/PPPP1PPP/RNBQKB1R b KQkq - 1 2",
+		"rnbq1bnr/ppppkppp/8/4p3/4P3/5N2/PPPP1PPP/RNBQKB1R w KQ - 2 3",
+	}
+
+	for i, move := range moves {
+		board.MakeMove(move)
+		if board.ToFEN() != fens[i] {
+			t.Errorf("wrong position after %s, got fen:\n%s", move, board.ToFEN())
+		}
+	}
+}
+
+func TestBitBoard_MakeMoveCapture(t *testing.T) {
+	board := CreateEmptyBitBoard()
+	board.PlacePieceOnBoard(4, 0, WHITE_KING)
+	board.PlacePieceOnBoard(4, 7, BLACK_KING)
+	board.PlacePieceOnBoard(7, 7, BLACK_ROOK)
+	board.PlacePieceOnBoard(7, 0, WHITE_ROOK)
+	board.SetTurn(10)
+	board.halfmove = 7
+
+	move := NewMove(7, 0, 7, 7)
+	move.Flags = CAPTURE
+	board.MakeMove(move)
+
+	if board.GetPieceOnField(7, 7) != WHITE_ROOK || !board.isFieldEmpty(7, 0) {
+		t.Error("capture hasn't been executed")
+	}
+	if board.blackCastleKing || !board.blackCastleQueen {
+		t.Error("capturing the rook should only remove black's king side castle rights")
+	}
+	if board.whiteCastleKing || !board.whiteCastleQueen {
+		t.Error("moving the rook should only remove white's king side castle rights")
+	}
+	if board.halfmove != 0 || board.GetTurn() != 10 || board.IsWhitesTurn() {
+		t.Error("counters or side to move are wrong after capture")
+	}
+}
+
+func TestBitBoard_MakeMoveEnPassant(t *testing.T) {
+	board := CreateEmptyBitBoard()
+	board.PlacePieceOnBoard(4, 0, WHITE_KING)
+	board.PlacePieceOnBoard(4, 7, BLACK_KING)
+	board.PlacePieceOnBoard(4, 4, WHITE_PAWN)
+	board.PlacePieceOnBoard(3, 4, BLACK_PAWN)
+	board.SetEnPassant(3, 5)
+
+	original := board.Copy()
+	move := NewMove(4, 4, 3, 5)
+	move.Flags = CAPTURE | EN_PASSANT
+	undo := board.MakeMove(move)
+
+	if !board.isFieldEmpty(3, 4) || board.GetPieceOnField(3, 5) != WHITE_PAWN {
+		t.Error("en passant hasn't removed the captured pawn")
+	}
+	if board.GetEnPassant()[0] != -1 || board.GetEnPassant()[1] != -1 {
+		t.Error("en passant field should have been cleared")
+	}
+
+	board.UnmakeMove(move, undo)
+	if !board.Equal(original) {
+		t.Error("unmaking en passant didn't restore the position")
+	}
+}
+
+func TestBitBoard_UnmakeMove(t *testing.T) {
+	board := GetStartBoard()
+	board.MakeMove(NewMove(4, 1, 4, 3))
+	board.MakeMove(NewMove(3, 6, 3, 4))
+
+	for _, move := range board.LegalMoves() {
+		original := board.Copy()
+		undo := board.MakeMove(move)
+		for _, reply := range board.LegalMoves() {
+			before := board.Copy()
+			replyUndo := board.MakeMove(reply)
+			board.UnmakeMove(reply, replyUndo)
+			if !board.Equal(before) {
+				t.Errorf("unmaking %s after %s didn't restore the position", reply, move)
+			}
+		}
+		board.UnmakeMove(move, undo)
+		if !board.Equal(original) {
+			t.Errorf("unmaking %s didn't restore the position", move)
+		}
+	}
+}
+
+func TestBitBoard_MakeMoveCastle(t *testing.T) {
+	board := castleBoard()
+	original := board.Copy()
+
+	kingSide := NewMove(4, 0, 6, 0)
+	kingSide.Flags = CASTLE
+	if !containsMove(board.LegalMoves(), kingSide) {
+		t.Fatal("king side castling is missing from the legal moves")
+	}
+	whiteUndo := board.MakeMove(kingSide)
+	if board.ToFEN() != "r3k2r/8/8/8/8/8/8/R4RK1 b kq - 1 0" {
+		t.Errorf("wrong position after castling, got fen:\n%s", board.ToFEN())
+	}
+
+	queenSide := NewMove(4, 7, 2, 7)
+	queenSide.Flags = CASTLE
+	if !containsMove(board.LegalMoves(), queenSide) {
+		t.Fatal("queen side castling is missing from the legal moves")
+	}
+	afterWhite := board.Copy()
+	blackUndo := board.MakeMove(queenSide)
+	if board.ToFEN() != "2kr3r/8/8/8/8/8/8/R4RK1 w - - 2 1" {
+		t.Errorf("wrong position after castling, got fen:\n%s", board.ToFEN())
+	}
+
+	board.UnmakeMove(queenSide, blackUndo)
+	if !board.Equal(afterWhite) {
+		t.Error("unmaking queen side castling didn't restore the position")
+	}
+	board.UnmakeMove(kingSide, whiteUndo)
+	if !board.Equal(original) {
+		t.Error("unmaking king side castling didn't restore the position")
+	}
+}
+
+func TestBitBoard_LegalMovesPromotion(t *testing.T) {
+	board := FromFEN("r3k3/1P6/8/8/8/8/6p1/4K1BN w - - 0 1")
+	moves := board.LegalMoves()
+
+	promotions := 0
+	captures := 0
+	for _, move := range moves {
+		if move.IsPromotion() {
+			promotions++
+			if !move.Promotion.IsWhite() {
+				t.Errorf("white pawn promoted to black piece in %s", move)
+			}
+			if move.IsCapture() {
+				captures++
+			}
+		}
+	}
+	if promotions != 8 || captures != 4 {
+		t.Errorf("expected 8 promotions of which 4 capture, got %d and %d", promotions, captures)
+	}
+
+	board = FromFEN("r3k3/1P6/8/8/8/8/6p1/4K1BN b - - 0 1")
+	promotions = 0
+	for _, move := range board.LegalMoves() {
+		if move.IsPromotion() {
+			promotions++
+			if !move.Promotion.IsBlack() || !move.IsCapture() || move.ToX != 7 {
+				t.Errorf("invalid black promotion %s", move)
+			}
+		}
+	}
+	if promotions != 4 {
+		t.Errorf("pawn on g2 is blocked and can only promote by capturing, got %d promotions", promotions)
+	}
+}
+
+func TestBitBoard_MakeMovePromotion(t *testing.T) {
+	fen := "r3k3/1P6/8/8/8/8/6p1/4K1BN w - - 0 1"
+	board := FromFEN(fen)
+
+	move := NewMove(1, 6, 1, 7)
+	move.Promotion = WHITE_KNIGHT
+	undo := board.MakeMove(move)
+	if board.ToFEN() != "rN2k3/8/8/8/8/8/6p1/4K1BN b - - 0 1" {
+		t.Errorf("wrong position after promotion, got fen:\n%s", board.ToFEN())
+	}
+	board.UnmakeMove(move, undo)
+	if board.ToFEN() != fen {
+		t.Errorf("unmaking promotion didn't restore the position, got fen:\n%s", board.ToFEN())
+	}
+
+	move = NewMove(1, 6, 0, 7)
+	move.Promotion = WHITE_QUEEN
+	move.Flags = CAPTURE
+	undo = board.MakeMove(move)
+	if board.ToFEN() != "Q3k3/8/8/8/8/8/6p1/4K1BN b - - 0 1" {
+		t.Errorf("wrong position after capturing promotion, got fen:\n%s", board.ToFEN())
+	}
+
+	reply := NewMove(6, 1, 7, 0)
+	reply.Promotion = BLACK_ROOK
+	reply.Flags = CAPTURE
+	replyUndo := board.MakeMove(reply)
+	if board.ToFEN() != "Q3k3/8/8/8/8/8/8/4K1Br w - - 0 2" {
+		t.Errorf("wrong position after black promotion, got fen:\n%s", board.ToFEN())
+	}
+
+	board.UnmakeMove(reply, replyUndo)
+	board.UnmakeMove(move, undo)
+	if board.ToFEN() != fen {
+		t.Errorf("unmaking promotions didn't restore the position, got fen:\n%s", board.ToFEN())
+	}
+}
+
+func TestBitBoard_CaptureMoves(t *testing.T) {
+	for _, position := range perftPositions {
+		board := FromFEN(position.fen)
+
+		var expected []Move
+		for _, move := range board.LegalMoves() {
+			if move.IsCapture() || move.IsPromotion() {
+				expected = append(expected, move)
+			}
+		}
+
+		captures := board.CaptureMoves()
+		if len(captures) != len(expected) {
+			t.Errorf("expected %d captures, got %d in %s", len(expected), len(captures), position.fen)
+		}
+		for _, move := range expected {
+			if !containsMove(captures, move) {
+				t.Errorf("capture %s is missing in %s", move, position.fen)
+			}
+		}
+	}
+
+	// en passant and a promotion without capture, the pinned knight can't take
+	board := FromFEN("4k3/1P6/8/3pP3/8/2p5/8/r2NK3 w - d6 0 2")
+	captures := board.CaptureMoves()
+	if len(captures) != 5 || !containsMove(captures, Move{4, 4, 3, 5, NO_PIECE, CAPTURE | EN_PASSANT}) {
+		t.Errorf("expected en passant and 4 promotions, got %v", captures)
+	}
+}
+
+func TestParseUCIMove(t *testing.T) {
+	board := FromFEN("r3k3/1P6/8/8/8/8/4P3/4K2R w K - 0 1")
+
+	valid := map[string]Move{
+		"e2e4":  {4, 1, 4, 3, NO_PIECE, DOUBLE_PUSH},
+		"e1g1":  {4, 0, 6, 0, NO_PIECE, CASTLE},
+		"b7b8q": {1, 6, 1, 7, WHITE_QUEEN, 0},
+		"b7a8n": {1, 6, 0, 7, WHITE_KNIGHT, CAPTURE},
+	}
+	for text, expected := range valid {
+		move, err := ParseUCIMove(&board, text)
+		if err != nil || move != expected {
+			t.Errorf("%s should be %+v, got %+v (%v)", text, expected, move, err)
+		}
+		if move.UCI() != text {
+			t.Errorf("%+v should be written as %s, got %s", move, text, move.UCI())
+		}
+	}
+
+	for _, text := range []string{"", "e2", "e2e5", "e2e4q", "b7b8", "b7b8k", "b7b8Q", "i2i4", "e2e4e6", "e1h1"} {
+		if move, err := ParseUCIMove(&board, text); err == nil {
+			t.Errorf("%q should be rejected, got %s", text, move)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package board
+
+import "math/bits"
+
+const lastRanks = 0xFF000000000000FF
+
+// generateMoves finds the legal moves without trying them on a copy of the board. Pinned pieces only move along the
+// line to their king, in check only moves that capture or block the checker are generated and in double check only
+// the king moves. capturesOnly leaves out everything but captures and promotions.
+func (board *BitBoard) generateMoves(capturesOnly bool) []Move {
+	moves := make([]Move, 0, 64)
+
+	white := board.whitesTurn
+	own, enemy := board.black, board.white
+	king := board.pieces[BLACK_KING]
+	if white {
+		own, enemy = board.white, board.black
+		king = board.pieces[WHITE_KING]
+	}
+
+	kingSquare := -1
+	var checkers, pinned uint64
+	if king != 0 {
+		kingSquare = bits.TrailingZeros64(king)
+		checkers = board.AttackersTo(kingSquare, !white)
+		pinned = board.pinnedPieces(kingSquare, white)
+	}
+
+	// fields the other pieces may move to, in check only the ones resolving it
+	allowed := ^own
+	if bits.OnesCount64(checkers) == 1 {
+		checker := bits.TrailingZeros64(checkers)
+		allowed &= checkers | betweenTable[kingSquare][checker]
+	}
+	captures := ^uint64(0)
+	if capturesOnly {
+		captures = enemy
+	}
+
+	if bits.OnesCount64(checkers) < 2 {
+		for pieces := own &^ king; pieces != 0; pieces &= pieces - 1 {
+			from := bits.TrailingZeros64(pieces)
+			piece := board.GetPieceOnField(from%8, from/8)
+
+			var targets uint64
+			switch piece % 6 {
+			case BLACK_PAWN:
+				targets = board.pawnTargets(from, white, capturesOnly) & allowed
+			case BLACK_KNIGHT:
+				targets = knightAttacks(from) & allowed & captures
+			case BLACK_BISHOP:
+				targets = bishopAttacks(from, board.occupied) & allowed & captures
+			case BLACK_ROOK:
+				targets = rookAttacks(from, board.occupied) & allowed & captures
+			case BLACK_QUEEN:
+				targets = (rookAttacks(from, board.occupied) | bishopAttacks(from, board.occupied)) & allowed & captures
+			}
+			if pinned&(1<<uint(from)) != 0 {
+				targets &= lineTable[kingSquare][from]
+			}
+
+			moves = board.appendMoves(moves, piece, from, targets)
+
+			if piece%6 == BLACK_PAWN {
+				moves = board.appendEnPassant(moves, piece, from, white)
+			}
+		}
+	}
+
+	if king != 0 {
+		kingPiece := BLACK_KING
+		if white {
+			kingPiece = WHITE_KING
+		}
+
+		// the king must not stay on the ray of a slider by stepping away from it
+		occupied := board.occupied &^ king
+		var targets uint64
+		for candidates := kingAttacks(kingSquare) &^ own & captures; candidates != 0; candidates &= candidates - 1 {
+			to := bits.TrailingZeros64(candidates)
+			if board.attackersTo(to, occupied)&enemy == 0 {
+				targets |= 1 << uint(to)
+			}
+		}
+
+		if !capturesOnly && checkers == 0 {
+			castles := CreateEmptyBitBoard()
+			addCastleMoves(&castles, board, white)
+			targets |= castles.occupied
+		}
+
+		moves = board.appendMoves(moves, kingPiece, kingSquare, targets)
+	}
+
+	return moves
+}
+
+// pinnedPieces are the pieces of the given side that stand alone between their king and an enemy slider
+func (board *BitBoard) pinnedPieces(kingSquare int, white bool) uint64 {
+	own, enemy := board.black, board.white
+	rooks := board.pieces[WHITE_ROOK] | board.pieces[WHITE_QUEEN]
+	bishops := board.pieces[WHITE_BISHOP] | board.pieces[WHITE_QUEEN]
+	if white {
+		own, enemy = board.white, board.black
+		rooks = board.pieces[BLACK_ROOK] | board.pieces[BLACK_QUEEN]
+		bishops = board.pieces[BLACK_BISHOP] | board.pieces[BLACK_QUEEN]
+	}
+
+	// the own pieces are looked through to find the sliders behind them
+	snipers := rookAttacks(kingSquare, enemy)&rooks | bishopAttacks(kingSquare, enemy)&bishops
+
+	var pinned uint64
+	for ; snipers != 0; snipers &= snipers - 1 {
+		blockers := betweenTable[kingSquare][bits.TrailingZeros64(snipers)] & board.occupied
+		if bits.OnesCount64(blockers) == 1 && blockers&own != 0 {
+			pinned |= blockers
+		}
+	}
+	return pinned
+}
+
+// pawnTargets are the pushes and captures of a pawn without en passant, with capturesOnly only captures and
+// promotions
+func (board *BitBoard) pawnTargets(from int, white, capturesOnly bool) uint64 {
+	x, y := from%8, from/8
+	direction, startRank, enemy := -1, 6, board.white
+	if white {
+		direction, startRank, enemy = 1, 1, board.black
+	}
+
+	var pushes uint64
+	if board.isFieldEmpty(x, y+direction) {
+		pushes |= squareMask(x, y+direction)
+		if y == startRank && board.isFieldEmpty(x, y+2*direction) {
+			pushes |= squareMask(x, y+2*direction)
+		}
+	}
+	if capturesOnly {
+		pushes &= lastRanks
+	}
+
+	return pushes | pawnAttacks(from, white)&enemy
+}
+
+// appendEnPassant adds the en passant capture of the pawn on from. It's checked on a copy of the board since taking
+// away two pawns at once may open a rank to the king, which the pins don't cover.
+func (board *BitBoard) appendEnPassant(moves []Move, piece Piece, from int, white bool) []Move {
+	x, y := board.enPassant[0], board.enPassant[1]
+	if x < 0 || (white && y != 5) || (!white && y != 2) || pawnAttacks(from, white)&squareMask(x, y) == 0 {
+		return moves
+	}
+
+	if board.doesMoveResultInCheck(from%8, from/8, x, y, white) {
+		return moves
+	}
+	return board.appendMoves(moves, piece, from, squareMask(x, y))
+}
+
+// enPassantTarget is the en passant field if the side to move has a legal en passant capture and -1, -1 otherwise.
+// A field nobody can capture on doesn't change the position, neither for repetitions nor for the hash.
+func (board *BitBoard) enPassantTarget() [2]int {
+	x, y := board.enPassant[0], board.enPassant[1]
+	white := board.whitesTurn
+	if x < 0 || (white && y != 5) || (!white && y != 2) {
+		return [2]int{-1, -1}
+	}
+
+	pawns := board.pieces[BLACK_PAWN]
+	if white {
+		pawns = board.pieces[WHITE_PAWN]
+	}
+	for from := pawnAttacks(y*8+x, !white) & pawns; from != 0; from &= from - 1 {
+		square := bits.TrailingZeros64(from)
+		if !board.doesMoveResultInCheck(square%8, square/8, x, y, white) {
+			return board.enPassant
+		}
+	}
+	return [2]int{-1, -1}
+}
+
+func (board *BitBoard) appendMoves(moves []Move, piece Piece, from int, targets uint64) []Move {
+	for ; targets != 0; targets &= targets - 1 {
+		to := bits.TrailingZeros64(targets)
+		move := board.newMoveWithFlags(piece, from%8, from/8, to%8, to/8)
+		if (piece == WHITE_PAWN && move.ToY == 7) || (piece == BLACK_PAWN && move.ToY == 0) {
+			moves = appendPromotions(moves, move, piece.IsWhite())
+			continue
+		}
+		moves = append(moves, move)
+	}
+	return moves
+}
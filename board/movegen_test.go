@@ -0,0 +1,132 @@
+package board
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// filteredMoves is the old way of finding legal moves, every move of the movement matrices is tried on a copy
+func filteredMoves(board *BitBoard) []Move {
+	var moves []Move
+	for square := 0; square < 64; square++ {
+		x, y := square%8, square/8
+		piece := board.GetPieceOnField(x, y)
+		if piece.IsNone() || piece.IsWhite() != board.whitesTurn {
+			continue
+		}
+		matrix := piece.GetMovementMatrix(board, x, y, false)
+		moves = board.appendMoves(moves, piece, square, matrix.occupied)
+	}
+	return moves
+}
+
+func perftFiltered(board *BitBoard, depth int) uint64 {
+	if depth == 0 {
+		return 1
+	}
+	var nodes uint64
+	for _, move := range filteredMoves(board) {
+		undo := board.MakeMove(move)
+		nodes += perftFiltered(board, depth-1)
+		board.UnmakeMove(move, undo)
+	}
+	return nodes
+}
+
+func sameMoves(a, b []Move) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, move := range a {
+		if !containsMove(b, move) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestBitBoard_GenerateMovesPerftParity(t *testing.T) {
+	for _, position := range perftPositions {
+		board := FromFEN(position.fen)
+		if nodes, filtered := board.Perft(2), perftFiltered(&board, 2); nodes != filtered {
+			t.Errorf("%s: %d nodes instead of %d", position.name, nodes, filtered)
+		}
+	}
+
+	random := rand.New(rand.NewSource(2))
+	for game := 0; game < 40; game++ {
+		board := FromFEN(perftPositions[game%len(perftPositions)].fen)
+		for ply := 0; ply < 60; ply++ {
+			moves := board.LegalMoves()
+			if !sameMoves(moves, filteredMoves(&board)) {
+				t.Fatalf("moves differ in %s: %v instead of %v", board.ToFEN(), moves, filteredMoves(&board))
+			}
+			if len(moves) == 0 {
+				break
+			}
+			board.MakeMove(moves[random.Intn(len(moves))])
+		}
+	}
+}
+
+func TestBitBoard_GenerateMovesEvasions(t *testing.T) {
+	// double check by knight and rook, only the king may move
+	board := FromFEN("4r1k1/8/8/8/8/3n4/8/R3K3 w Q - 0 1")
+	for _, move := range board.LegalMoves() {
+		if move.FromX != 4 || move.FromY != 0 {
+			t.Errorf("only the king may move in double check, got %s", move)
+		}
+	}
+
+	// the rook on the e-file can be blocked or taken
+	board = FromFEN("4r1k1/8/8/8/8/8/3B4/R3K1N1 w Q - 0 1")
+	moves := board.LegalMoves()
+	for _, text := range []string{"d2e3", "g1e2", "e1d1", "e1f1", "e1f2"} {
+		found := false
+		for _, move := range moves {
+			if move.String() == text {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("evasion %s is missing in %v", text, moves)
+		}
+	}
+	if len(moves) != 5 {
+		t.Errorf("expected 5 evasions, got %v", moves)
+	}
+}
+
+func TestBitBoard_GenerateMovesPins(t *testing.T) {
+	// the bishop is pinned on the file, the rook may move along it
+	board := FromFEN("4r1k1/8/8/8/8/4B3/8/4K3 w - - 0 1")
+	for _, move := range board.LegalMoves() {
+		if move.FromX == 4 && move.FromY == 2 {
+			t.Errorf("pinned bishop moved: %s", move)
+		}
+	}
+
+	board = FromFEN("4r1k1/8/8/8/8/4R3/8/4K3 w - - 0 1")
+	for _, move := range board.LegalMoves() {
+		if move.FromX == 4 && move.FromY == 2 && move.ToX != 4 {
+			t.Errorf("pinned rook left the file: %s", move)
+		}
+	}
+	if board.pinnedPieces(4, true) != squareMask(4, 2) {
+		t.Error("rook should be pinned")
+	}
+}
+
+func TestBitBoard_GenerateMovesEnPassant(t *testing.T) {
+	// taking en passant would clear the rank between king and rook
+	board := FromFEN("8/8/8/KPp4r/8/8/8/7k w - c6 0 2")
+	if containsMove(board.LegalMoves(), Move{1, 4, 2, 5, NO_PIECE, CAPTURE | EN_PASSANT}) {
+		t.Error("en passant exposes the king")
+	}
+
+	// en passant takes the pawn giving check
+	board = FromFEN("8/8/8/2k5/3Pp3/8/8/4K3 b - d3 0 1")
+	if !containsMove(board.LegalMoves(), Move{4, 3, 3, 2, NO_PIECE, CAPTURE | EN_PASSANT}) {
+		t.Error("en passant should capture the checking pawn")
+	}
+}
@@ -0,0 +1,59 @@
+package board
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+func (board *BitBoard) Perft(depth int) uint64 {
+	if depth <= 0 {
+		return 1
+	}
+
+	moves := board.LegalMoves()
+	if depth == 1 {
+		return uint64(len(moves))
+	}
+
+	var nodes uint64
+	for _, move := range moves {
+		undo := board.MakeMove(move)
+		nodes += board.Perft(depth - 1)
+		board.UnmakeMove(move, undo)
+	}
+
+	return nodes
+}
+
+// Divide returns the perft node count below every root move
+func (board *BitBoard) Divide(depth int) map[Move]uint64 {
+	result := make(map[Move]uint64)
+	for _, move := range board.LegalMoves() {
+		undo := board.MakeMove(move)
+		result[move] = board.Perft(depth - 1)
+		board.UnmakeMove(move, undo)
+	}
+
+	return result
+}
+
+// PrintDivide writes one "move: nodes" line per root move, sorted by move, followed by the total
+func (board *BitBoard) PrintDivide(w io.Writer, depth int) uint64 {
+	divide := board.Divide(depth)
+
+	lines := make([]string, 0, len(divide))
+	var total uint64
+	for move, nodes := range divide {
+		lines = append(lines, fmt.Sprintf("%s: %d", move, nodes))
+		total += nodes
+	}
+	sort.Strings(lines)
+
+	for _, line := range lines {
+		fmt.Fprintln(w, line)
+	}
+	fmt.Fprintf(w, "\nNodes searched: %d\n", total)
+
+	return total
+}
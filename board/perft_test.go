@@ -0,0 +1,75 @@
+package board
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// node counts from https://www.chessprogramming.org/Perft_Results
+var perftPositions = []struct {
+	name   string
+	fen    string
+	counts []uint64
+}{
+	{"start position", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", []uint64{20, 400, 8902, 197281}},
+	{"kiwipete", "r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1", []uint64{48, 2039, 97862, 4085603}},
+	{"position 3", "8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1", []uint64{14, 191, 2812, 43238}},
+	{"position 4", "r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1", []uint64{6, 264, 9467, 422333}},
+	{"position 5", "rnbq1k1r/pp1Pbppp/2p5/8/2B5/8/PPP1NnPP/RNBQK2R w KQ - 1 8", []uint64{44, 1486, 62379, 2103487}},
+	{"position 6", "r4rk1/1pp1qppp/p1np1n2/2b1p1B1/2B1P1b1/P1NP1N2/1PP1QPPP/R4RK1 w - - 0 10", []uint64{46, 2079, 89890, 3894594}},
+}
+
+func TestBitBoard_Perft(t *testing.T) {
+	for _, position := range perftPositions {
+		board := FromFEN(position.fen)
+		for i, expected := range position.counts {
+			if nodes := board.Perft(i + 1); nodes != expected {
+				t.Errorf("%s: perft(%d) is %d, expected %d", position.name, i+1, nodes, expected)
+			}
+		}
+		if board.ToFEN() != position.fen {
+			t.Errorf("%s: perft changed the position to %s", position.name, board.ToFEN())
+		}
+	}
+}
+
+func TestBitBoard_Divide(t *testing.T) {
+	board := GetStartBoard()
+	divide := board.Divide(3)
+
+	if len(divide) != 20 {
+		t.Errorf("expected 20 root moves, got %d", len(divide))
+	}
+
+	var total uint64
+	for _, nodes := range divide {
+		total += nodes
+	}
+	if total != board.Perft(3) {
+		t.Errorf("divide sums up to %d instead of %d", total, board.Perft(3))
+	}
+
+	doublePush := NewMove(4, 1, 4, 3)
+	doublePush.Flags = DOUBLE_PUSH
+	if divide[doublePush] != 600 {
+		t.Errorf("expected 600 nodes after e2e4, got %d", divide[doublePush])
+	}
+}
+
+func TestBitBoard_PrintDivide(t *testing.T) {
+	board := GetStartBoard()
+	var output bytes.Buffer
+
+	if board.PrintDivide(&output, 2) != 400 {
+		t.Error("wrong total for divide")
+	}
+
+	lines := strings.Split(output.String(), "\n")
+	if lines[0] != "a2a3: 20" {
+		t.Errorf("root moves not sorted, first line is %s", lines[0])
+	}
+	if !strings.Contains(output.String(), "g1f3: 20\n") || !strings.HasSuffix(output.String(), "Nodes searched: 400\n") {
+		t.Errorf("unexpected divide output:\n%s", output.String())
+	}
+}
@@ -1,5 +1,7 @@
 package board
 
+import "math/bits"
+
 type Piece int
 
 const (
@@ -44,7 +46,7 @@ func (piece Piece) GetMovementMatrix(board *BitBoard, x, y int, allowCheck bool)
 	case BLACK_KNIGHT:
 		movementMatrix = getKnightMatrix(board, x, y, false)
 	case WHITE_KNIGHT:
-		movementMatrix = getKnightMatrix(board, x, y, false)
+		movementMatrix = getKnightMatrix(board, x, y, true)
 	case BLACK_BISHOP:
 		movementMatrix = getBishopMatrix(board, x, y, false)
 	case WHITE_BISHOP:
@@ -54,9 +56,9 @@ func (piece Piece) GetMovementMatrix(board *BitBoard, x, y int, allowCheck bool)
 	case WHITE_QUEEN:
 		movementMatrix = getQueenMatrix(board, x, y, true)
 	case BLACK_KING:
-		movementMatrix = getKingMatrix(board, x, y, false)
+		movementMatrix = getKingMatrix(board, x, y, false, !allowCheck)
 	case WHITE_KING:
-		movementMatrix = getKingMatrix(board, x, y, true)
+		movementMatrix = getKingMatrix(board, x, y, true, !allowCheck)
 	}
 
 	if !allowCheck {
@@ -82,13 +84,11 @@ func GetPieceByNotation(s string) Piece {
 }
 
 func removeInvalidMoves(matrix *BitBoard, board *BitBoard, x, y int, white bool) {
-	for i, row := range matrix.board {
-		for j := range row {
-			if !matrix.isFieldEmpty(i, j) {
-				if board.doesMoveResultInCheck(x, y, i, j, white) {
-					matrix.PlacePieceOnBoard(i, j, NO_PIECE)
-				}
-			}
+	for moves := matrix.occupied; moves != 0; moves &= moves - 1 {
+		square := bits.TrailingZeros64(moves)
+		i, j := square%8, square/8
+		if board.doesMoveResultInCheck(x, y, i, j, white) {
+			matrix.PlacePieceOnBoard(i, j, NO_PIECE)
 		}
 	}
 }
@@ -104,250 +104,114 @@ func getPawnMatrix(board *BitBoard, x, y int, white bool) BitBoard {
 		piece = BLACK_PAWN
 	}
 	matrix := CreateEmptyBitBoard()
-	if board.isFieldAvailable(x, y + direction, white) {
-		matrix.board[x][y + direction][piece] = true
-		if (white && (y == 1)) || (!white && (y == 6)) {
-			matrix.board[x][y + 2 * direction][piece] = board.isFieldAvailable(x, y + 2 * direction, white)
+	if board.isFieldEmpty(x, y + direction) {
+		matrix.PlacePieceOnBoard(x, y + direction, piece)
+		if ((white && (y == 1)) || (!white && (y == 6))) && board.isFieldEmpty(x, y + 2 * direction) {
+			matrix.PlacePieceOnBoard(x, y + 2 * direction, piece)
 		}
 	}
 
-	if x > 0 {
-		if board.isFieldAvailable(x - 1, y + direction, white) && !board.isFieldEmpty(x - 1, y + direction) ||
-			(((white && y > 3) || (!white && y < 4)) && ((board.GetEnPassant()[0] == x - 1) &&
-				(board.GetEnPassant()[1] == y + direction))) {
-			matrix.PlacePieceOnBoard(x - 1, y + direction, piece)
-		}
+	enemies := board.white
+	if white {
+		enemies = board.black
 	}
-	if x < 7 {
-		if board.isFieldAvailable(x + 1, y + direction, white) && !board.isFieldEmpty(x + 1, y + direction) ||
-			(((white && y > 3) || (!white && y < 4)) && ((board.GetEnPassant()[0] == x + 1) &&
-				(board.GetEnPassant()[1] == y + direction))) {
-			matrix.PlacePieceOnBoard(x + 1, y + direction, piece)
-		}
+	// the en passant field belongs to the side that didn't just move
+	if board.enPassant[0] >= 0 && (white && board.enPassant[1] == 5 || !white && board.enPassant[1] == 2) {
+		enemies |= squareMask(board.enPassant[0], board.enPassant[1])
 	}
+	matrix.pieces[piece] |= pawnAttacks(y*8+x, white) & enemies
+	matrix.updateOccupancy()
 
-
-return matrix
+	return matrix
 }
 
 func getRookMatrix(board *BitBoard, x, y int, white bool) BitBoard {
-	var piece Piece
+	piece := BLACK_ROOK
 	if white {
 		piece = WHITE_ROOK
-	} else {
-		piece = BLACK_ROOK
 	}
-	matrix := CreateEmptyBitBoard()
-
-	for i := x + 1; i < 8; i++ {
-		if !board.isFieldEmpty(i, y) {
-			if board.isFieldAvailable(i, y, white) {
-				matrix.PlacePieceOnBoard(i, y, piece)
-			}
-			break
-		}
-		matrix.PlacePieceOnBoard(i, y, piece)
-	}
-
-	for i := x - 1; i >= 0; i-- {
-		if !board.isFieldEmpty(i, y) {
-			if board.isFieldAvailable(i, y, white) {
-				matrix.PlacePieceOnBoard(i, y, piece)
-			}
-			break
-		}
-		matrix.PlacePieceOnBoard(i, y, piece)
-	}
-
-	for j := y + 1; j < 8; j++ {
-		if !board.isFieldEmpty(x, j) {
-			if board.isFieldAvailable(x, j, white) {
-				matrix.PlacePieceOnBoard(x, j, piece)
-			}
-			break
-		}
-		matrix.PlacePieceOnBoard(x, j, piece)
-	}
-
-	for j := y - 1; j >= 0; j-- {
-		if !board.isFieldEmpty(x, j) {
-			if board.isFieldAvailable(x, j, white) {
-				matrix.PlacePieceOnBoard(x, j, piece)
-			}
-			break
-		}
-		matrix.PlacePieceOnBoard(x, j, piece)
-	}
-
-	return matrix
+	return attackMatrix(board, piece, rookAttacks(y*8+x, board.occupied), white)
 }
 
 func getKnightMatrix(board *BitBoard, x, y int, white bool) BitBoard {
-	var piece Piece
+	piece := BLACK_KNIGHT
 	if white {
-		piece = WHITE_ROOK
-	} else {
-		piece = BLACK_ROOK
-	}
-
-	matrix := CreateEmptyBitBoard()
-
-	if x + 2 < 8 {
-		if y + 1 < 8 {
-			matrix.board[x + 2][y + 1][piece] = board.isFieldAvailable(x + 2, y + 1, white)
-		}
-		if y - 1 >= 0 {
-			matrix.board[x + 2][y - 1][piece] = board.isFieldAvailable(x + 2, y - 1, white)
-		}
-	}
-
-	if x - 2 >= 0 {
-		if y + 1 < 8 {
-			matrix.board[x - 2][y + 1][piece] = board.isFieldAvailable(x - 2, y + 1, white)
-		}
-		if y - 1 >= 0 {
-			matrix.board[x - 2][y - 1][piece] = board.isFieldAvailable(x - 2, y - 1, white)
-		}
-	}
-
-	if y + 2 < 8 {
-		if x + 1 < 8 {
-			matrix.board[x + 1][y + 2][piece] = board.isFieldEmpty(x + 1, y + 2)
-		}
-		if x - 1 >= 0 {
-			matrix.board[x - 1][y + 2][piece] = board.isFieldEmpty(x - 1, y + 2)
-		}
-	}
-
-	if y - 2 >= 0 {
-		if x + 1 < 8 {
-			matrix.board[x + 1][y - 2][piece] = board.isFieldEmpty(x + 1, y - 2)
-		}
-		if x - 1 >= 0 {
-			matrix.board[x - 1][y - 2][piece] = board.isFieldEmpty(x - 1, y - 2)
-		}
+		piece = WHITE_KNIGHT
 	}
-
-	return matrix
+	return attackMatrix(board, piece, knightAttacks(y*8+x), white)
 }
 
 func getBishopMatrix(board *BitBoard, x, y int, white bool) BitBoard {
-	var piece Piece
+	piece := BLACK_BISHOP
 	if white {
 		piece = WHITE_BISHOP
-	} else {
-		piece = BLACK_BISHOP
-	}
-	movementMatrix := CreateEmptyBitBoard()
-	b1 := y - x
-	b2 := y + x
-
-	for i := x + 1; i < 8; i++ {
-		yi := b1 + i
-		if  yi >= 8 || yi < 0 {
-			break
-		}
-		if !board.isFieldEmpty(i, yi) {
-			if board.isFieldAvailable(i, yi, white) {
-				movementMatrix.PlacePieceOnBoard(i, yi, piece)
-			}
-			break
-		}
-		movementMatrix.PlacePieceOnBoard(i, yi, piece)
-	}
-
-	for i := x + 1; i < 8; i++ {
-		yi := b2 - i
-		if  yi >= 8 || yi < 0 {
-			break
-		}
-		if !board.isFieldEmpty(i, yi) {
-			if board.isFieldAvailable(i, yi, white) {
-				movementMatrix.PlacePieceOnBoard(i, yi, piece)
-			}
-			break
-		}
-		movementMatrix.PlacePieceOnBoard(i, yi, piece)
 	}
+	return attackMatrix(board, piece, bishopAttacks(y*8+x, board.occupied), white)
+}
 
-	for i := x - 1; i >= 0; i-- {
-		yi := b1 + i
-		if  yi >= 8 || yi < 0 {
-			break
-		}
-		if !board.isFieldEmpty(i, yi) {
-			if board.isFieldAvailable(i, yi, white) {
-				movementMatrix.PlacePieceOnBoard(i, yi, piece)
-			}
-			break
-		}
-		movementMatrix.PlacePieceOnBoard(i, yi, piece)
+// castling can never capture, so it's left out when only attacked fields are of interest
+func getKingMatrix(board *BitBoard, x, y int, white, castle bool) BitBoard {
+	piece := BLACK_KING
+	if white {
+		piece = WHITE_KING
 	}
+	movementMatrix := attackMatrix(board, piece, kingAttacks(y*8+x), white)
 
-	for i := x - 1; i >= 0; i-- {
-		yi := b2 - i
-		if  yi >= 8 || yi < 0 {
-			break
-		}
-		if !board.isFieldEmpty(i, yi) {
-			if board.isFieldAvailable(i, yi, white) {
-				movementMatrix.PlacePieceOnBoard(i, yi, piece)
-			}
-			break
-		}
-		movementMatrix.PlacePieceOnBoard(i, yi, piece)
+	if castle {
+		addCastleMoves(&movementMatrix, board, white)
 	}
 
 	return movementMatrix
 }
 
-func getKingMatrix(board *BitBoard, x, y int, white bool) BitBoard {
-	var piece Piece
+func addCastleMoves(matrix *BitBoard, board *BitBoard, white bool) {
+	var king, rook Piece
+	var row int
+	var kingSide, queenSide bool
 	if white {
-		piece = WHITE_KING
+		king, rook, row = WHITE_KING, WHITE_ROOK, 0
+		kingSide, queenSide = board.whiteCastleKing, board.whiteCastleQueen
 	} else {
-		piece = BLACK_KING
+		king, rook, row = BLACK_KING, BLACK_ROOK, 7
+		kingSide, queenSide = board.blackCastleKing, board.blackCastleQueen
 	}
-	movementMatrix := CreateEmptyBitBoard()
 
-	for i := -1; i < 2; i++ {
-		for j := -1; j < 2; j++ {
-			xi := x + i
-			yi := y + j
-			if !((i == x) && (j == y)) && xi < 8 && xi >= 0 && yi < 8 && yi >= 0 {
-				if board.isFieldAvailable(xi, yi, white) {
-					movementMatrix.PlacePieceOnBoard(xi, yi, piece)
-				}
-			}
-		}
+	if !board.isPieceOnField(4, row, king) || board.isFieldAttacked(4, row, white) {
+		return
 	}
 
-	return movementMatrix
+	if kingSide && board.isPieceOnField(7, row, rook) &&
+		board.isFieldEmpty(5, row) && board.isFieldEmpty(6, row) &&
+		!board.isFieldAttacked(5, row, white) && !board.isFieldAttacked(6, row, white) {
+		matrix.PlacePieceOnBoard(6, row, king)
+	}
+
+	if queenSide && board.isPieceOnField(0, row, rook) &&
+		board.isFieldEmpty(1, row) && board.isFieldEmpty(2, row) && board.isFieldEmpty(3, row) &&
+		!board.isFieldAttacked(2, row, white) && !board.isFieldAttacked(3, row, white) {
+		matrix.PlacePieceOnBoard(2, row, king)
+	}
 }
 
 func getQueenMatrix(board *BitBoard, x, y int, white bool) BitBoard {
-	var piece Piece
-	var movementMatrixBishop BitBoard
-	var movementMatrixRook BitBoard
+	piece := BLACK_QUEEN
 	if white {
 		piece = WHITE_QUEEN
-		movementMatrixBishop = WHITE_BISHOP.GetMovementMatrix(board, x, y, true)
-		movementMatrixRook = WHITE_ROOK.GetMovementMatrix(board, x, y, true)
-	} else {
-		movementMatrixBishop = BLACK_BISHOP.GetMovementMatrix(board, x, y, true)
-		movementMatrixRook = BLACK_ROOK.GetMovementMatrix(board, x, y, true)
-		piece = BLACK_QUEEN
 	}
+	square := y*8 + x
+	return attackMatrix(board, piece, rookAttacks(square, board.occupied)|bishopAttacks(square, board.occupied), white)
+}
 
-	movementMatrix := CreateEmptyBitBoard()
-	for i, row := range movementMatrix.board {
-		for j := range row {
-		    if !movementMatrixRook.isFieldEmpty(i, j) || !movementMatrixBishop.isFieldEmpty(i, j) {
-		    	movementMatrix.PlacePieceOnBoard(i, j, piece)
-			}
-		}
+// attackMatrix is the movement matrix of piece attacking the given fields, without the ones of its own color
+func attackMatrix(board *BitBoard, piece Piece, attacks uint64, white bool) BitBoard {
+	own := board.black
+	if white {
+		own = board.white
 	}
 
+	matrix := CreateEmptyBitBoard()
+	matrix.pieces[piece] = attacks &^ own
+	matrix.updateOccupancy()
 
-	return movementMatrix
+	return matrix
 }
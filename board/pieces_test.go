@@ -12,10 +12,10 @@ func TestGetMovementMatrixPawn(t *testing.T) {
 
 	movementMatrix = WHITE_PAWN.GetMovementMatrix(&board, 0, 1, false)
 
-	for i, row := range movementMatrix.board {
-		for j, field := range row {
-			for k, value := range field {
-				if value != ((i == 0) && (k == int(WHITE_PAWN)) && ((j == 2) || (j == 3))) {
+	for i := 0; i < 8; i++ {
+		for j := 0; j < 8; j++ {
+			for k := 0; k < 12; k++ {
+				if movementMatrix.isPieceOnField(i, j, Piece(k)) != ((i == 0) && (k == int(WHITE_PAWN)) && ((j == 2) || (j == 3))) {
 					t.Error("movement matrix is wrong")
 				}
 			}
@@ -24,10 +24,10 @@ func TestGetMovementMatrixPawn(t *testing.T) {
 
 	movementMatrix = BLACK_PAWN.GetMovementMatrix(&board, 0, 6, false)
 
-	for i, row := range movementMatrix.board {
-		for j, field := range row {
-			for k, value := range field {
-				if value != ((i == 0) && (k == int(BLACK_PAWN)) && ((j == 5) || (j == 4))) {
+	for i := 0; i < 8; i++ {
+		for j := 0; j < 8; j++ {
+			for k := 0; k < 12; k++ {
+				if movementMatrix.isPieceOnField(i, j, Piece(k)) != ((i == 0) && (k == int(BLACK_PAWN)) && ((j == 5) || (j == 4))) {
 					t.Error("movement matrix is wrong")
 				}
 			}
@@ -36,8 +36,8 @@ func TestGetMovementMatrixPawn(t *testing.T) {
 
 	board.PlacePieceOnBoard(0, 5, BLACK_PAWN)
 	movementMatrix = BLACK_PAWN.GetMovementMatrix(&board, 0, 6, false)
-	for i, row := range movementMatrix.board {
-		for j := range row {
+	for i := 0; i < 8; i++ {
+		for j := 0; j < 8; j++ {
 			if !movementMatrix.isFieldEmpty(i, j) {
 				t.Error("movement matrix should be empty")
 			}
@@ -46,8 +46,8 @@ func TestGetMovementMatrixPawn(t *testing.T) {
 
 	board.PlacePieceOnBoard(1, 5, WHITE_PAWN)
 	movementMatrix = BLACK_PAWN.GetMovementMatrix(&board, 0, 6, false)
-	for i, row := range movementMatrix.board {
-		for j := range row {
+	for i := 0; i < 8; i++ {
+		for j := 0; j < 8; j++ {
 			if (i == 1 && j == 5) && movementMatrix.isFieldEmpty(i, j) {
 				t.Error("invalid movement matrix, pawn should be able to move and capture")
 			}
@@ -67,8 +67,8 @@ func TestPiece_GetMovementMatrixRook(t *testing.T) {
 
 	movementMatrix := BLACK_ROOK.GetMovementMatrix(&board, 7, 7, false)
 
-	for i, row := range movementMatrix.board {
-		for j := range row {
+	for i := 0; i < 8; i++ {
+		for j := 0; j < 8; j++ {
 			if !movementMatrix.isFieldEmpty(i, j) {
 				t.Error("movement matrix wrong, rook is blocked in every direction")
 			}
@@ -80,7 +80,7 @@ func TestPiece_GetMovementMatrixRook(t *testing.T) {
 	movementMatrix = WHITE_ROOK.GetMovementMatrix(&board, 0, 0, false)
 
 	for i := 1; i < 8; i++ {
-		if !movementMatrix.board[0][i][WHITE_ROOK] || !movementMatrix.board[i][0][WHITE_ROOK] {
+		if !movementMatrix.isPieceOnField(0, i, WHITE_ROOK) || !movementMatrix.isPieceOnField(i, 0, WHITE_ROOK) {
 			t.Error("movement matrix wrong for rook in corner")
 		}
 	}
@@ -89,11 +89,11 @@ func TestPiece_GetMovementMatrixRook(t *testing.T) {
 	movementMatrix = WHITE_ROOK.GetMovementMatrix(&board, 0, 0, false)
 	for i := 1; i < 8; i++ {
 		if i < 4 {
-			if !movementMatrix.board[0][i][WHITE_ROOK] {
+			if !movementMatrix.isPieceOnField(0, i, WHITE_ROOK) {
 				t.Error("movement matrix wrong for rook in corner blocked by piece")
 			}
 		} else {
-			if movementMatrix.board[0][i][WHITE_ROOK] {
+			if movementMatrix.isPieceOnField(0, i, WHITE_ROOK) {
 				t.Error("movement matrix wrong for rook in corner blocked by piece")
 			}
 
@@ -106,8 +106,8 @@ func TestPiece_GetMovementMatrixKnight(t *testing.T) {
 
 	movementMatrix := BLACK_KNIGHT.GetMovementMatrix(&board, 1, 7, false)
 
-	for i, row := range movementMatrix.board {
-		for j := range row {
+	for i := 0; i < 8; i++ {
+		for j := 0; j < 8; j++ {
 			if !(movementMatrix.isFieldEmpty(i, j) == !((j == 5) && ((i == 0) || (i == 2)))) {
 				t.Error("movement matrix for knight wrong")
 			}
@@ -130,8 +130,8 @@ func TestPiece_GetMovementMatrixBishop(t *testing.T) {
 	board := GetStartBoard()
 
 	movementMatrix := BLACK_BISHOP.GetMovementMatrix(&board, 2, 7, false)
-	for i, row := range movementMatrix.board {
-		for j := range row {
+	for i := 0; i < 8; i++ {
+		for j := 0; j < 8; j++ {
 			if !movementMatrix.isFieldEmpty(i, j) {
 				t.Error("movement matrix for bishop in start pos wrong")
 			}
@@ -142,8 +142,8 @@ func TestPiece_GetMovementMatrixBishop(t *testing.T) {
 	board.PlacePieceOnBoard(4, 4, BLACK_BISHOP)
 	movementMatrix = BLACK_BISHOP.GetMovementMatrix(&board, 4, 4, false)
 
-	for i, row := range movementMatrix.board {
-		for j := range row {
+	for i := 0; i < 8; i++ {
+		for j := 0; j < 8; j++ {
 		    if ((i == j) || (j == (8 - i))) && (i != 4) && (j != 4) {
 		    	if movementMatrix.isFieldEmpty(i, j) {
 					t.Error("movement matrix invalid, piece should be able to move here")
@@ -171,8 +171,8 @@ func TestPiece_GetMovementMatrixQueen(t *testing.T) {
 	board := GetStartBoard()
 
 	movementMatrix := BLACK_QUEEN.GetMovementMatrix(&board, 3, 7, false)
-	for i, row := range movementMatrix.board {
-		for j := range row {
+	for i := 0; i < 8; i++ {
+		for j := 0; j < 8; j++ {
 			if !movementMatrix.isFieldEmpty(i, j) {
 				t.Error("movement matrix for queen in start pos wrong")
 			}
@@ -183,8 +183,8 @@ func TestPiece_GetMovementMatrixQueen(t *testing.T) {
 	board.PlacePieceOnBoard(4, 4, BLACK_QUEEN)
 	movementMatrix = BLACK_QUEEN.GetMovementMatrix(&board, 4, 4, false)
 
-	for i, row := range movementMatrix.board {
-		for j := range row {
+	for i := 0; i < 8; i++ {
+		for j := 0; j < 8; j++ {
 			rookField := (i == 4) || (j == 4)
 			bishopField := ((i == j) || (j == (8 - i))) && (i != 4) && (j != 4)
 			if (rookField || bishopField) && !((i == 4) && (j == 4)) {
@@ -202,8 +202,8 @@ func TestPiece_GetMovementMatrixKing(t *testing.T) {
     board := GetStartBoard()
     movementMatrix := BLACK_KING.GetMovementMatrix(&board, 4, 7, false)
 
-	for i, row := range movementMatrix.board {
-		for j := range row {
+	for i := 0; i < 8; i++ {
+		for j := 0; j < 8; j++ {
 		    if !movementMatrix.isFieldEmpty(i, j) {
 				t.Error("movement matrix should be empty")
 		    }
@@ -214,8 +214,8 @@ func TestPiece_GetMovementMatrixKing(t *testing.T) {
 	board.PlacePieceOnBoard(4, 4, BLACK_KING)
 	movementMatrix = BLACK_KING.GetMovementMatrix(&board, 4, 4, false)
 
-	for i, row := range movementMatrix.board {
-		for j := range row {
+	for i := 0; i < 8; i++ {
+		for j := 0; j < 8; j++ {
 		    if int(math.Abs(float64(4 - i)) - 1) <= 0 && int(math.Abs(float64(4 - j)) - 1) <= 0 { // go needs floats for abs?
 		    	if movementMatrix.isFieldEmpty(i, j) && !((i == 4) && (j == 4)) {
 		    		t.Error("invalid movement matrix, piece should be able to move here")
@@ -235,6 +235,83 @@ func TestPiece_GetMovementMatrixKing(t *testing.T) {
 
 }
 
+func castleBoard() BitBoard {
+	board := CreateEmptyBitBoard()
+	board.PlacePieceOnBoard(4, 0, WHITE_KING)
+	board.PlacePieceOnBoard(0, 0, WHITE_ROOK)
+	board.PlacePieceOnBoard(7, 0, WHITE_ROOK)
+	board.PlacePieceOnBoard(4, 7, BLACK_KING)
+	board.PlacePieceOnBoard(0, 7, BLACK_ROOK)
+	board.PlacePieceOnBoard(7, 7, BLACK_ROOK)
+	return board
+}
+
+func TestPiece_GetMovementMatrixKingCastle(t *testing.T) {
+	board := castleBoard()
+
+	movementMatrix := WHITE_KING.GetMovementMatrix(&board, 4, 0, false)
+	if movementMatrix.isFieldEmpty(6, 0) || movementMatrix.isFieldEmpty(2, 0) {
+		t.Error("white should be able to castle on both sides")
+	}
+	movementMatrix = BLACK_KING.GetMovementMatrix(&board, 4, 7, false)
+	if movementMatrix.isFieldEmpty(6, 7) || movementMatrix.isFieldEmpty(2, 7) {
+		t.Error("black should be able to castle on both sides")
+	}
+
+	movementMatrix = WHITE_KING.GetMovementMatrix(&board, 4, 0, true)
+	if !movementMatrix.isFieldEmpty(6, 0) || !movementMatrix.isFieldEmpty(2, 0) {
+		t.Error("castling should not be part of the attacked fields")
+	}
+
+	board.whiteCastleKing = false
+	movementMatrix = WHITE_KING.GetMovementMatrix(&board, 4, 0, false)
+	if !movementMatrix.isFieldEmpty(6, 0) || movementMatrix.isFieldEmpty(2, 0) {
+		t.Error("white lost the right to castle king side")
+	}
+
+	board = castleBoard()
+	board.PlacePieceOnBoard(1, 0, WHITE_KNIGHT)
+	board.PlacePieceOnBoard(5, 7, BLACK_BISHOP)
+	movementMatrix = WHITE_KING.GetMovementMatrix(&board, 4, 0, false)
+	if !movementMatrix.isFieldEmpty(2, 0) {
+		t.Error("queen side castling is blocked by the knight")
+	}
+	if movementMatrix.isFieldEmpty(6, 0) {
+		t.Error("king side castling isn't affected by the bishop on f8")
+	}
+
+	board = castleBoard()
+	board.PlacePieceOnBoard(5, 4, BLACK_ROOK)
+	movementMatrix = WHITE_KING.GetMovementMatrix(&board, 4, 0, false)
+	if !movementMatrix.isFieldEmpty(6, 0) {
+		t.Error("king can't castle through an attacked field")
+	}
+	if movementMatrix.isFieldEmpty(2, 0) {
+		t.Error("queen side castling should still be possible")
+	}
+
+	board = castleBoard()
+	board.PlacePieceOnBoard(0, 2, BLACK_KNIGHT)
+	movementMatrix = WHITE_KING.GetMovementMatrix(&board, 4, 0, false)
+	if movementMatrix.isFieldEmpty(6, 0) || movementMatrix.isFieldEmpty(2, 0) {
+		t.Error("attacked fields the king doesn't cross don't matter for castling")
+	}
+
+	board = castleBoard()
+	board.PlacePieceOnBoard(6, 1, BLACK_PAWN)
+	movementMatrix = WHITE_KING.GetMovementMatrix(&board, 4, 0, false)
+	if !movementMatrix.isFieldEmpty(6, 0) {
+		t.Error("pawn on g2 attacks f1, king can't castle king side")
+	}
+
+	board = castleBoard()
+	board.PlacePieceOnBoard(4, 4, BLACK_ROOK)
+	movementMatrix = WHITE_KING.GetMovementMatrix(&board, 4, 0, false)
+	if !movementMatrix.isFieldEmpty(6, 0) || !movementMatrix.isFieldEmpty(2, 0) {
+		t.Error("king can't castle out of check")
+	}
+}
+
 func TestPiece_IsColor(t *testing.T) {
 	if !BLACK_KING.IsBlack() {
 		t.Error("Black king incorrectly classified")
@@ -269,8 +346,8 @@ func TestPiece_MoveInCheck(t *testing.T) {
 
 	movementMatrix = WHITE_BISHOP.GetMovementMatrix(&board, 1, 0, false)
 
-	for i, row := range movementMatrix.board {
-		for j := range row {
+	for i := 0; i < 8; i++ {
+		for j := 0; j < 8; j++ {
 			if !movementMatrix.isFieldEmpty(i, j) {
 				t.Error("bishop should not be able to move because king is in check")
 			}
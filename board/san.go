@@ -0,0 +1,158 @@
+package board
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ToSAN writes a legal move in standard algebraic notation like Nbd7, exd6, O-O-O, e8=Q+ or Qh4#
+func (board *BitBoard) ToSAN(move Move) string {
+	piece := board.GetPieceOnField(move.FromX, move.FromY)
+	target := RowColToAlgebra(move.ToX, move.ToY)
+
+	var san string
+	switch {
+	case move.IsCastle() && move.ToX == 6:
+		san = "O-O"
+	case move.IsCastle():
+		san = "O-O-O"
+	case piece%6 == BLACK_PAWN:
+		if move.IsCapture() {
+			san = RowColToAlgebra(move.FromX, move.FromY)[:1] + "x"
+		}
+		san += target
+		if move.IsPromotion() {
+			san += "=" + strings.ToUpper(move.Promotion.GetNotation())
+		}
+	default:
+		san = strings.ToUpper(piece.GetNotation()) + board.disambiguation(move, piece)
+		if move.IsCapture() {
+			san += "x"
+		}
+		san += target
+	}
+
+	after := board.Copy()
+	after.MakeMove(move)
+	if after.IsCheck(after.whitesTurn) {
+		if len(after.LegalMoves()) == 0 {
+			return san + "#"
+		}
+		return san + "+"
+	}
+	return san
+}
+
+// disambiguation is the file, the rank or both of the starting field if another piece of the same kind could move
+// to the same field, the file is preferred
+func (board *BitBoard) disambiguation(move Move, piece Piece) string {
+	ambiguous, sameFile, sameRank := false, false, false
+	for _, other := range board.LegalMoves() {
+		if other.ToX != move.ToX || other.ToY != move.ToY || (other.FromX == move.FromX && other.FromY == move.FromY) ||
+			board.GetPieceOnField(other.FromX, other.FromY) != piece {
+			continue
+		}
+		ambiguous = true
+		sameFile = sameFile || other.FromX == move.FromX
+		sameRank = sameRank || other.FromY == move.FromY
+	}
+
+	from := RowColToAlgebra(move.FromX, move.FromY)
+	switch {
+	case !ambiguous:
+		return ""
+	case !sameFile:
+		return from[:1]
+	case !sameRank:
+		return from[1:]
+	default:
+		return from
+	}
+}
+
+// ParseSAN finds the legal move written in standard algebraic notation. Check and annotation marks are ignored,
+// castling may be written with zeros and the = of a promotion may be left out.
+func (board *BitBoard) ParseSAN(san string) (Move, error) {
+	text := strings.TrimRight(san, "+#!?")
+	if text == "" {
+		return Move{}, fmt.Errorf("empty move")
+	}
+
+	moves := board.LegalMoves()
+
+	switch text {
+	case "O-O", "0-0":
+		return findSANMove(san, moves, func(move Move) bool { return move.IsCastle() && move.ToX == 6 })
+	case "O-O-O", "0-0-0":
+		return findSANMove(san, moves, func(move Move) bool { return move.IsCastle() && move.ToX == 2 })
+	}
+
+	kind := BLACK_PAWN
+	if strings.ContainsRune("NBRQK", rune(text[0])) {
+		kind = GetPieceByNotation(strings.ToLower(text[:1]))
+		text = text[1:]
+	}
+
+	promotion := NO_PIECE
+	if kind == BLACK_PAWN && len(text) > 2 && strings.ContainsRune("NBRQ", rune(text[len(text)-1])) {
+		promotion = GetPieceByNotation(strings.ToLower(text[len(text)-1:]))
+		text = strings.TrimSuffix(text[:len(text)-1], "=")
+	}
+
+	if len(text) < 2 {
+		return Move{}, fmt.Errorf("invalid move %q", san)
+	}
+	toX, toY, err := ParseSquare(text[len(text)-2:])
+	if err != nil {
+		return Move{}, fmt.Errorf("invalid move %q: %v", san, err)
+	}
+	text = text[:len(text)-2]
+
+	capture := strings.HasSuffix(text, "x")
+	text = strings.TrimSuffix(text, "x")
+
+	// what's left can only be the disambiguation
+	fromX, fromY := -1, -1
+	for _, c := range text {
+		switch {
+		case c >= 'a' && c <= 'h' && fromX == -1 && fromY == -1:
+			fromX = int(c - 'a')
+		case c >= '1' && c <= '8' && fromY == -1:
+			fromY = int(c - '1')
+		default:
+			return Move{}, fmt.Errorf("invalid move %q", san)
+		}
+	}
+
+	return findSANMove(san, moves, func(move Move) bool {
+		piece := board.GetPieceOnField(move.FromX, move.FromY)
+		if piece%6 != kind || move.ToX != toX || move.ToY != toY || (capture && !move.IsCapture()) {
+			return false
+		}
+		if (fromX != -1 && move.FromX != fromX) || (fromY != -1 && move.FromY != fromY) {
+			return false
+		}
+		if promotion == NO_PIECE {
+			return !move.IsPromotion()
+		}
+		return move.IsPromotion() && move.Promotion%6 == promotion%6
+	})
+}
+
+func findSANMove(san string, moves []Move, matches func(Move) bool) (Move, error) {
+	var found []Move
+	for _, move := range moves {
+		if matches(move) {
+			found = append(found, move)
+		}
+	}
+
+	switch len(found) {
+	case 0:
+		return Move{}, fmt.Errorf("illegal move %s", san)
+	case 1:
+		return found[0], nil
+	default:
+		return Move{}, fmt.Errorf("ambiguous move %s", san)
+	}
+}
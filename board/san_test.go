@@ -0,0 +1,113 @@
+package board
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestBitBoard_ToSAN(t *testing.T) {
+	tests := []struct {
+		fen  string
+		move string
+		san  string
+	}{
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", "e2e4", "e4"},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", "g1f3", "Nf3"},
+		// knights on b8 and f6 can both go to d7
+		{"rnbqkb1r/ppp2ppp/3p1n2/4p3/4P3/5N2/PPPP1PPP/RNBQKB1R b KQkq - 0 1", "b8d7", "Nbd7"},
+		// rooks on the same file
+		{"4k3/8/8/R7/8/8/8/R3K3 w - - 0 1", "a1a3", "R1a3"},
+		// three queens, file and rank are needed
+		{"8/8/6k1/8/8/Q7/8/Q1Q1K3 w - - 0 1", "a1b2", "Qa1b2"},
+		{"4k3/8/8/3pP3/8/8/8/4K3 w - d6 0 2", "e5d6", "exd6"},
+		{"r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1", "e1g1", "O-O"},
+		{"r3k2r/8/8/8/8/8/8/R3K2R b KQkq - 0 1", "e8c8", "O-O-O"},
+		{"4k3/1P6/8/8/8/8/8/4K3 w - - 0 1", "b7b8q", "b8=Q+"},
+		{"2r1k3/1P6/8/8/8/8/8/4K3 w - - 0 1", "b7c8n", "bxc8=N"},
+		{"rnbqkbnr/pppp1ppp/8/4p3/6P1/5P2/PPPPP2P/RNBQKBNR b KQkq - 0 2", "d8h4", "Qh4#"},
+		// the knight on g1 is pinned, so there is nothing to disambiguate
+		{"4k3/8/8/8/8/8/8/2N1K1Nr w - - 0 1", "c1e2", "Ne2"},
+	}
+
+	for _, test := range tests {
+		board := FromFEN(test.fen)
+		move := findMoveByString(t, &board, test.move)
+		if san := board.ToSAN(move); san != test.san {
+			t.Errorf("expected %s for %s in %s, got %s", test.san, test.move, test.fen, san)
+		}
+
+		parsed, err := board.ParseSAN(test.san)
+		if err != nil || parsed != move {
+			t.Errorf("%s should parse to %s, got %s (%v)", test.san, test.move, parsed, err)
+		}
+	}
+}
+
+func findMoveByString(t *testing.T, board *BitBoard, text string) Move {
+	for _, move := range board.LegalMoves() {
+		if move.String() == text {
+			return move
+		}
+	}
+	t.Fatalf("%s is not legal in %s", text, board.ToFEN())
+	return Move{}
+}
+
+func TestBitBoard_ParseSAN(t *testing.T) {
+	board := FromFEN("r3k2r/8/8/8/8/8/4P3/R3K2R w KQkq - 0 1")
+	lenient := map[string]string{
+		"0-0":   "e1g1",
+		"O-O-O": "e1c1",
+		"e4!?":  "e2e4",
+		"Rad1":  "a1d1",
+		"Rxa8+": "a1a8",
+		"Ra1a8": "a1a8",
+	}
+	for san, expected := range lenient {
+		if move, err := board.ParseSAN(san); err != nil || move.String() != expected {
+			t.Errorf("%s should be %s, got %s (%v)", san, expected, move, err)
+		}
+	}
+
+	board = FromFEN("4k3/1P6/8/8/8/8/8/4K3 w - - 0 1")
+	if move, err := board.ParseSAN("b8Q"); err != nil || move.Promotion != WHITE_QUEEN {
+		t.Errorf("promotion without = should be accepted, got %s (%v)", move, err)
+	}
+
+	invalid := []string{"", "e5", "Nf3", "b8", "Kd9", "Rxd1", "Zd1", "e4e4e4", "O-O-O-O"}
+	board = FromFEN("r3k2r/8/8/8/8/8/4P3/R3K2R w KQkq - 0 1")
+	for _, san := range invalid {
+		if move, err := board.ParseSAN(san); err == nil {
+			t.Errorf("%q should be rejected, got %s", san, move)
+		}
+	}
+
+	// both rooks reach d1
+	board = FromFEN("4k3/8/8/8/8/8/K7/R6R w - - 0 1")
+	if _, err := board.ParseSAN("Rd1"); err == nil {
+		t.Error("Rd1 is ambiguous")
+	}
+	if _, err := board.ParseSAN("Rhd1"); err != nil {
+		t.Errorf("Rhd1 is unique: %v", err)
+	}
+}
+
+func TestBitBoard_SANRoundTrip(t *testing.T) {
+	random := rand.New(rand.NewSource(3))
+	for game := 0; game < 20; game++ {
+		board := FromFEN(perftPositions[game%len(perftPositions)].fen)
+		for ply := 0; ply < 60; ply++ {
+			moves := board.LegalMoves()
+			if len(moves) == 0 {
+				break
+			}
+			for _, move := range moves {
+				san := board.ToSAN(move)
+				if parsed, err := board.ParseSAN(san); err != nil || parsed != move {
+					t.Fatalf("%s doesn't survive a round trip as %s in %s: %v", move, san, board.ToFEN(), err)
+				}
+			}
+			board.MakeMove(moves[random.Intn(len(moves))])
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package board
+
+import "math/bits"
+
+// values of the piece types in centipawns for the exchange evaluation, in the order pawn, rook, knight, bishop,
+// queen, king. The king is worth more than everything else so that giving it up never pays off.
+var seeValues = [6]int{100, 500, 320, 330, 900, 20000}
+
+// the order in which attackers join an exchange, least valuable first
+var seeOrder = [6]Piece{BLACK_PAWN, BLACK_KNIGHT, BLACK_BISHOP, BLACK_ROOK, BLACK_QUEEN, BLACK_KING}
+
+// SEE resolves the sequence of captures on the target field of move, both sides always recapture with their least
+// valuable attacker and may stop when going on would lose material. The result is the material the side to move
+// wins in centipawns, negative for losing captures. Pins are not taken into account.
+func (board *BitBoard) SEE(move Move) int {
+	from := move.FromY*8 + move.FromX
+	to := move.ToY*8 + move.ToX
+	occupied := board.occupied &^ (1 << uint(from))
+
+	var gain [32]int
+	victim := board.GetPieceOnField(move.ToX, move.ToY)
+	if victim != NO_PIECE {
+		gain[0] = seeValues[victim%6]
+	}
+	if move.IsEnPassant() {
+		gain[0] = seeValues[BLACK_PAWN]
+		occupied &^= squareMask(move.ToX, move.FromY)
+	}
+
+	// the piece standing on the field after the move is the next one to be captured
+	attacker := board.GetPieceOnField(move.FromX, move.FromY)
+	if move.IsPromotion() {
+		attacker = move.Promotion
+		gain[0] += seeValues[attacker%6] - seeValues[BLACK_PAWN]
+	}
+
+	white := !attacker.IsWhite()
+	depth := 0
+	for depth < len(gain)-1 {
+		attackers := board.attackersTo(to, occupied) & occupied
+		own := attackers & board.black
+		if white {
+			own = attackers & board.white
+		}
+		if own == 0 {
+			break
+		}
+
+		next, square := board.leastValuableAttacker(own)
+
+		// the king may only take if nothing can take back
+		if next%6 == BLACK_KING && attackers&^own != 0 {
+			break
+		}
+
+		depth++
+		gain[depth] = seeValues[attacker%6] - gain[depth-1]
+		attacker = next
+		occupied &^= 1 << uint(square)
+		white = !white
+	}
+
+	// every side may also stop instead of recapturing
+	for ; depth > 0; depth-- {
+		if -gain[depth] < gain[depth-1] {
+			gain[depth-1] = -gain[depth]
+		}
+	}
+
+	return gain[0]
+}
+
+func (board *BitBoard) leastValuableAttacker(attackers uint64) (Piece, int) {
+	for _, kind := range seeOrder {
+		for _, piece := range []Piece{kind, kind + 6} {
+			if pieces := board.pieces[piece] & attackers; pieces != 0 {
+				return piece, bits.TrailingZeros64(pieces)
+			}
+		}
+	}
+	return NO_PIECE, -1
+}
@@ -0,0 +1,41 @@
+package board
+
+import "testing"
+
+func TestBitBoard_SEE(t *testing.T) {
+	tests := []struct {
+		fen  string
+		move Move
+		see  int
+	}{
+		// undefended pawn
+		{"1k1r4/1pp4p/p7/4p3/8/P5P1/1PP4P/2K1R3 w - - 0 1", Move{4, 0, 4, 4, NO_PIECE, CAPTURE}, 100},
+		// knight for a pawn, all the other attackers don't matter
+		{"1k1r3q/1ppn3p/p4b2/4p3/8/P2N2P1/1PP1R1BP/2K1Q3 w - - 0 1", Move{3, 2, 4, 4, NO_PIECE, CAPTURE}, -220},
+		// the rook behind the first one takes back
+		{"4r1k1/8/8/4p3/8/8/4R3/4R1K1 w - - 0 1", Move{4, 1, 4, 4, NO_PIECE, CAPTURE}, 100},
+		// queen behind the bishop
+		{"6k1/8/5p2/8/8/2B5/1Q6/6K1 w - - 0 1", Move{2, 2, 5, 5, NO_PIECE, CAPTURE}, 100},
+		// queen for a pawn
+		{"6k1/8/5p2/4p3/8/8/4Q3/6K1 w - - 0 1", Move{4, 1, 4, 4, NO_PIECE, CAPTURE}, -800},
+		// the king can't take back a defended piece
+		{"3qk3/8/8/8/8/8/8/3RK3 w - - 0 1", Move{3, 0, 3, 7, NO_PIECE, CAPTURE}, 900 - 500},
+		{"3qk3/8/8/B7/8/8/8/3RK3 w - - 0 1", Move{3, 0, 3, 7, NO_PIECE, CAPTURE}, 900},
+		// en passant
+		{"4k3/8/8/3pP3/8/8/8/4K3 w - d6 0 2", Move{4, 4, 3, 5, NO_PIECE, CAPTURE | EN_PASSANT}, 100},
+		// promotion on a defended field
+		{"3rk3/2P5/8/8/8/8/8/4K3 w - - 0 1", Move{2, 6, 2, 7, WHITE_QUEEN, 0}, 800 - 900},
+		{"3rk3/2P5/8/8/8/8/8/4K3 w - - 0 1", Move{2, 6, 3, 7, WHITE_QUEEN, CAPTURE}, 500 + 800 - 900},
+		// quiet move onto an attacked field
+		{"4k3/8/8/3p4/8/8/8/2N1K3 w - - 0 1", Move{2, 0, 2, 2, NO_PIECE, 0}, 0},
+		{"4k3/8/8/3p4/8/8/8/1N2K3 w - - 0 1", Move{1, 0, 2, 2, NO_PIECE, 0}, 0},
+		{"4k3/8/8/8/3p4/8/8/1N2K3 w - - 0 1", Move{1, 0, 2, 2, NO_PIECE, 0}, -320},
+	}
+
+	for _, test := range tests {
+		board := FromFEN(test.fen)
+		if see := board.SEE(test.move); see != test.see {
+			t.Errorf("expected %d for %s in %s, got %d", test.see, test.move, test.fen, see)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package board
+
+import (
+	"math/bits"
+	"math/rand"
+)
+
+// random keys xor-ed together into the hash of a position, the seed is fixed so hashes are the same every run
+var pieceKeys [12][64]uint64
+var castleKeys [4]uint64
+var enPassantKeys [8]uint64
+var whitesTurnKey uint64
+
+func init() {
+	random := rand.New(rand.NewSource(0x7ce))
+	for piece := range pieceKeys {
+		for square := range pieceKeys[piece] {
+			pieceKeys[piece][square] = random.Uint64()
+		}
+	}
+	for i := range castleKeys {
+		castleKeys[i] = random.Uint64()
+	}
+	for i := range enPassantKeys {
+		enPassantKeys[i] = random.Uint64()
+	}
+	whitesTurnKey = random.Uint64()
+}
+
+// GetHash returns the zobrist key of the position, covering pieces, side to move, castle rights and en passant file
+// if en passant can be played.
+// Positions that are the same apart from the move counters have the same key.
+func (board *BitBoard) GetHash() uint64 {
+	return board.hash
+}
+
+// computeHash builds the key from scratch, MakeMove and the setters keep board.hash up to date incrementally
+func (board *BitBoard) computeHash() uint64 {
+	var hash uint64
+	for piece := range board.pieces {
+		for pieces := board.pieces[piece]; pieces != 0; pieces &= pieces - 1 {
+			hash ^= pieceKeys[piece][bits.TrailingZeros64(pieces)]
+		}
+	}
+
+	hash ^= board.castleHash() ^ board.enPassantHash()
+	if board.whitesTurn {
+		hash ^= whitesTurnKey
+	}
+
+	return hash
+}
+
+func (board *BitBoard) castleHash() uint64 {
+	var hash uint64
+	if board.whiteCastleKing {
+		hash ^= castleKeys[0]
+	}
+	if board.whiteCastleQueen {
+		hash ^= castleKeys[1]
+	}
+	if board.blackCastleKing {
+		hash ^= castleKeys[2]
+	}
+	if board.blackCastleQueen {
+		hash ^= castleKeys[3]
+	}
+	return hash
+}
+
+// enPassantHash depends on the pieces and the side to move as well, so it has to be taken out before and put back
+// after changing them
+func (board *BitBoard) enPassantHash() uint64 {
+	target := board.enPassantTarget()
+	if target[0] < 0 {
+		return 0
+	}
+	return enPassantKeys[target[0]]
+}
@@ -0,0 +1,110 @@
+package board
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestBitBoard_GetHash(t *testing.T) {
+	start := GetStartBoard()
+	if start.GetHash() != start.computeHash() || start.GetHash() == 0 {
+		t.Error("start position has a wrong hash")
+	}
+
+	// same position reached by a different move order
+	a := GetStartBoard()
+	for _, move := range []Move{NewMove(6, 0, 5, 2), NewMove(6, 7, 5, 5), NewMove(1, 0, 2, 2)} {
+		a.MakeMove(move)
+	}
+	b := GetStartBoard()
+	for _, move := range []Move{NewMove(1, 0, 2, 2), NewMove(6, 7, 5, 5), NewMove(6, 0, 5, 2)} {
+		b.MakeMove(move)
+	}
+	if a.GetHash() != b.GetHash() {
+		t.Error("transposed positions should have the same hash")
+	}
+
+	// knights out and back again
+	for _, move := range []Move{NewMove(2, 2, 1, 0), NewMove(5, 5, 6, 7), NewMove(1, 0, 2, 2), NewMove(6, 7, 5, 5)} {
+		a.MakeMove(move)
+	}
+	if a.GetHash() != b.GetHash() {
+		t.Error("move counters should not change the hash")
+	}
+
+	black := GetStartBoard()
+	black.SetWhitesTurn(false)
+	if black.GetHash() == start.GetHash() || black.GetHash() != black.computeHash() {
+		t.Error("side to move is not part of the hash")
+	}
+
+	noCastle := FromFEN("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w Kkq - 0 1")
+	if noCastle.GetHash() == start.GetHash() {
+		t.Error("castle rights are not part of the hash")
+	}
+
+	enPassant := FromFEN("rnbqkbnr/ppp1pppp/8/8/3pP3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 3")
+	noEnPassant := FromFEN("rnbqkbnr/ppp1pppp/8/8/3pP3/8/PPPP1PPP/RNBQKBNR b KQkq - 0 3")
+	if enPassant.GetHash() == noEnPassant.GetHash() {
+		t.Error("en passant file is not part of the hash")
+	}
+}
+
+func TestBitBoard_GetHashEnPassant(t *testing.T) {
+	// nobody can take on e3, the field doesn't change the position
+	withField := FromFEN("rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1")
+	withoutField := FromFEN("rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq - 0 1")
+	if withField.GetHash() != withoutField.GetHash() || !withField.SamePosition(withoutField) {
+		t.Error("en passant field without a capture should be ignored")
+	}
+
+	withField = FromFEN("rnbqkbnr/ppp1pppp/8/8/3pP3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1")
+	withoutField = FromFEN("rnbqkbnr/ppp1pppp/8/8/3pP3/8/PPPP1PPP/RNBQKBNR b KQkq - 0 1")
+	if withField.GetHash() == withoutField.GetHash() || withField.SamePosition(withoutField) {
+		t.Error("en passant field with a legal capture should count")
+	}
+
+	// the d4 pawn is pinned against its king, so the capture isn't legal
+	withField = FromFEN("8/8/8/8/1k1pP2R/8/8/4K3 b - e3 0 1")
+	withoutField = FromFEN("8/8/8/8/1k1pP2R/8/8/4K3 b - - 0 1")
+	if withField.GetHash() != withoutField.GetHash() {
+		t.Error("en passant field with an illegal capture should be ignored")
+	}
+}
+
+func TestBitBoard_GetHashRandomGames(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+
+	for game := 0; game < 50; game++ {
+		board := FromFEN("r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1")
+		if game%2 == 0 {
+			board = GetStartBoard()
+		}
+
+		var moves []Move
+		var undos []Undo
+		for ply := 0; ply < 80; ply++ {
+			legalMoves := board.LegalMoves()
+			if len(legalMoves) == 0 {
+				break
+			}
+			move := legalMoves[random.Intn(len(legalMoves))]
+			moves = append(moves, move)
+			undos = append(undos, board.MakeMove(move))
+
+			if board.GetHash() != board.computeHash() {
+				t.Fatalf("incremental hash differs after %s in %s", move, board.ToFEN())
+			}
+			if parsed := FromFEN(board.ToFEN()); parsed.GetHash() != board.GetHash() {
+				t.Fatalf("hash differs from the parsed fen %s", board.ToFEN())
+			}
+		}
+
+		for i := len(moves) - 1; i >= 0; i-- {
+			board.UnmakeMove(moves[i], undos[i])
+			if board.GetHash() != board.computeHash() {
+				t.Fatalf("incremental hash differs after taking back %s in %s", moves[i], board.ToFEN())
+			}
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"terrible_chess_computer/board"
+	"terrible_chess_computer/epd"
+	"terrible_chess_computer/search"
+)
+
+func main() {
+	depth := flag.Int("depth", 0, "number of plies to search per position, 0 for no limit")
+	moveTime := flag.Duration("movetime", 0, "time to search per position, like 500ms")
+	hash := flag.Int("hash", search.DEFAULT_HASH_SIZE, "size of the transposition table in MB")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: epd [flags] file.epd")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *depth <= 0 && *moveTime <= 0 {
+		*moveTime = time.Second
+	}
+
+	file, err := os.Open(flag.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	records, err := epd.ReadAll(file)
+	file.Close()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	searcher := search.NewSearcher()
+	searcher.SetHashSize(*hash)
+	run(os.Stdout, searcher, records, search.Limits{Depth: *depth, MoveTime: *moveTime})
+}
+
+// run searches every record and prints a line per record and the total, it returns the number of solved records
+func run(w io.Writer, searcher *search.Searcher, records []epd.Record, limits search.Limits) int {
+	solved := 0
+	start := time.Now()
+
+	for i, record := range records {
+		searcher.ClearHash()
+		result := searcher.Search(record.Position, limits, nil)
+
+		status := "failed"
+		if len(result.PV) > 0 && record.Solved(result.Move) {
+			status = "solved"
+			solved++
+		}
+
+		id := record.ID
+		if id == "" {
+			id = fmt.Sprintf("#%d", i+1)
+		}
+		played := "nothing"
+		if len(result.PV) > 0 {
+			played = record.Position.ToSAN(result.Move)
+		}
+		fmt.Fprintf(w, "%s %s: played %s, %s (depth %d, %d nodes)\n", status, id, played, expected(record),
+			result.Depth, result.Nodes)
+	}
+
+	fmt.Fprintf(w, "Solved %d of %d in %v\n", solved, len(records), time.Since(start).Round(time.Millisecond))
+	return solved
+}
+
+func expected(record epd.Record) string {
+	var parts []string
+	if len(record.BestMoves) > 0 {
+		parts = append(parts, "bm "+sanList(record.Position, record.BestMoves))
+	}
+	if len(record.AvoidMoves) > 0 {
+		parts = append(parts, "am "+sanList(record.Position, record.AvoidMoves))
+	}
+	if len(parts) == 0 {
+		return "no bm or am"
+	}
+	return strings.Join(parts, ", ")
+}
+
+func sanList(position board.BitBoard, moves []board.Move) string {
+	sans := make([]string, len(moves))
+	for i, move := range moves {
+		sans[i] = position.ToSAN(move)
+	}
+	return strings.Join(sans, " ")
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"terrible_chess_computer/epd"
+	"terrible_chess_computer/search"
+)
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name   string
+		suite  string
+		solved int
+		lines  []string
+	}{
+		{
+			name: "solved and failed",
+			suite: "6k1/5ppp/8/8/8/8/8/R5K1 w - - bm Ra8#; id \"mate\";\n" +
+				"6k1/5ppp/8/8/8/8/8/R5K1 w - - am Ra8#; id \"avoid mate\";\n",
+			solved: 1,
+			lines: []string{
+				"solved mate: played Ra8#, bm Ra8# (depth 1, ",
+				"failed avoid mate: played Ra8#, am Ra8# (depth 1, ",
+				"Solved 1 of 2 in ",
+			},
+		},
+		{
+			name:   "no id and no move",
+			suite:  "7k/5Q2/6K1/8/8/8/8/8 b - -\n4k3/8/8/8/8/8/4P3/4K3 w - -\n",
+			solved: 0,
+			lines: []string{
+				"failed #1: played nothing, no bm or am (",
+				"failed #2: played ",
+				"Solved 0 of 2 in ",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		records, err := epd.ReadAll(strings.NewReader(test.suite))
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+
+		var output bytes.Buffer
+		solved := run(&output, search.NewSearcher(), records, search.Limits{Depth: 1})
+		if solved != test.solved {
+			t.Errorf("%s: expected %d solved, got %d", test.name, test.solved, solved)
+		}
+
+		lines := strings.Split(strings.TrimSuffix(output.String(), "\n"), "\n")
+		if len(lines) != len(test.lines) {
+			t.Fatalf("%s: expected %d lines, got:\n%s", test.name, len(test.lines), output.String())
+		}
+		for i, prefix := range test.lines {
+			if !strings.HasPrefix(lines[i], prefix) {
+				t.Errorf("%s: line %d should start with %q, got %q", test.name, i+1, prefix, lines[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"terrible_chess_computer/board"
+)
+
+func main() {
+	fen := flag.String("fen", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", "position to start from")
+	depth := flag.Int("depth", 4, "number of plies to search")
+	divide := flag.Bool("divide", false, "print the node count for every root move")
+	flag.Parse()
+
+	position, err := board.ParseFEN(*fen)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	start := time.Now()
+
+	var nodes uint64
+	if *divide {
+		nodes = position.PrintDivide(os.Stdout, *depth)
+	} else {
+		nodes = position.Perft(*depth)
+		fmt.Printf("Nodes searched: %d\n", nodes)
+	}
+
+	elapsed := time.Since(start)
+	fmt.Printf("Time: %v (%.0f nps)\n", elapsed, float64(nodes)/elapsed.Seconds())
+}
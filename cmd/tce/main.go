@@ -0,0 +1,7 @@
+package main
+
+import "os"
+
+func main() {
+	newUCI(os.Stdout).run(os.Stdin)
+}
@@ -0,0 +1,347 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"terrible_chess_computer/board"
+	"terrible_chess_computer/game"
+	"terrible_chess_computer/search"
+)
+
+type goLimits struct {
+	depth     int
+	nodes     uint64
+	moveTime  time.Duration
+	wtime     time.Duration
+	btime     time.Duration
+	winc      time.Duration
+	binc      time.Duration
+	movesToGo int
+	infinite  bool
+}
+
+// time to use when there is a time control but our own clock wasn't sent
+const fallbackMoveTime = time.Second
+
+// timeForMove splits the remaining clock time over the moves still to play, keeping a bit in reserve. It's 0 if
+// there is no time control at all.
+func (limits goLimits) timeForMove(white bool) time.Duration {
+	if limits.moveTime > 0 {
+		return limits.moveTime
+	}
+
+	remaining, increment := limits.btime, limits.binc
+	if white {
+		remaining, increment = limits.wtime, limits.winc
+	}
+	if remaining <= 0 {
+		if limits.wtime > 0 || limits.btime > 0 || limits.winc > 0 || limits.binc > 0 {
+			return fallbackMoveTime
+		}
+		return 0
+	}
+
+	movesToGo := limits.movesToGo
+	if movesToGo <= 0 {
+		movesToGo = 30
+	}
+
+	budget := remaining/time.Duration(movesToGo) + increment*3/4
+	if limit := remaining - 50*time.Millisecond; budget > limit {
+		budget = limit
+	}
+	if budget < time.Millisecond {
+		budget = time.Millisecond
+	}
+
+	return budget
+}
+
+type uci struct {
+	out      io.Writer
+	outLock  sync.Mutex
+	game     *game.Game
+	searcher *search.Searcher
+	stop     chan struct{}
+	done     chan struct{}
+}
+
+func newUCI(out io.Writer) *uci {
+	return &uci{out: out, game: game.InitGame(), searcher: search.NewSearcher()}
+}
+
+func (u *uci) send(format string, args ...interface{}) {
+	u.outLock.Lock()
+	defer u.outLock.Unlock()
+	fmt.Fprintf(u.out, format+"\n", args...)
+}
+
+func (u *uci) run(in io.Reader) {
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch fields[0] {
+		case "uci":
+			u.send("id name tce")
+			u.send("id author the tce authors")
+			u.send("option name Hash type spin default %d min 1 max %d", search.DEFAULT_HASH_SIZE, maxHashSize)
+			u.send("uciok")
+		case "isready":
+			u.send("readyok")
+		case "ucinewgame":
+			u.stopSearch()
+			u.game = game.InitGame()
+			u.searcher.ClearHash()
+		case "setoption":
+			u.stopSearch()
+			if err := u.setOption(fields[1:]); err != nil {
+				u.send("info string %v", err)
+			}
+		case "position":
+			u.stopSearch()
+			if err := u.position(fields[1:]); err != nil {
+				u.send("info string %v", err)
+			}
+		case "go":
+			u.stopSearch()
+			limits, err := parseGo(fields[1:])
+			if err != nil {
+				// the GUI waits for a best move, so search a little anyway
+				u.send("info string %v, searching depth 1", err)
+				limits = goLimits{depth: 1}
+			}
+			u.startSearch(limits)
+		case "stop":
+			u.stopSearch()
+		case "quit":
+			u.stopSearch()
+			return
+		default:
+			u.send("info string unknown command %s", fields[0])
+		}
+	}
+	u.stopSearch()
+}
+
+const maxHashSize = 4096
+
+func (u *uci) setOption(args []string) error {
+	// setoption name <name> [value <value>], names may contain spaces
+	var name, value []string
+	target := &name
+	for _, arg := range args {
+		switch arg {
+		case "name":
+			target = &name
+		case "value":
+			target = &value
+		default:
+			*target = append(*target, arg)
+		}
+	}
+
+	switch strings.ToLower(strings.Join(name, " ")) {
+	case "hash":
+		size, err := strconv.Atoi(strings.Join(value, " "))
+		if err != nil || size < 1 || size > maxHashSize {
+			return fmt.Errorf("hash size must be between 1 and %d MB, got %q", maxHashSize, strings.Join(value, " "))
+		}
+		u.searcher.SetHashSize(size)
+	default:
+		return fmt.Errorf("unknown option %s", strings.Join(name, " "))
+	}
+
+	return nil
+}
+
+func (u *uci) position(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("position needs startpos or fen")
+	}
+
+	var newGame *game.Game
+	var rest []string
+	switch args[0] {
+	case "startpos":
+		newGame = game.InitGame()
+		rest = args[1:]
+	case "fen":
+		end := len(args)
+		for i, arg := range args {
+			if arg == "moves" {
+				end = i
+				break
+			}
+		}
+		position, err := board.ParseFEN(strings.Join(args[1:end], " "))
+		if err != nil {
+			return err
+		}
+		newGame = game.InitGameFromPosition(position)
+		rest = args[end:]
+	default:
+		return fmt.Errorf("unknown position type %s", args[0])
+	}
+
+	if len(rest) > 0 {
+		if rest[0] != "moves" {
+			return fmt.Errorf("expected moves, got %s", rest[0])
+		}
+		for _, text := range rest[1:] {
+			position := newGame.Position()
+			move, err := board.ParseUCIMove(&position, text)
+			if err != nil {
+				return err
+			}
+			if err := newGame.MakeMove(move); err != nil {
+				return err
+			}
+		}
+	}
+
+	u.game = newGame
+	return nil
+}
+
+var goParameters = map[string]bool{
+	"searchmoves": true, "ponder": true, "wtime": true, "btime": true, "winc": true, "binc": true, "movestogo": true,
+	"depth": true, "nodes": true, "mate": true, "movetime": true, "infinite": true,
+}
+
+// parseGo skips parameters it doesn't know or support, like ponder and searchmoves. go mate N searches as deep as a
+// mate in N needs.
+func parseGo(args []string) (goLimits, error) {
+	var limits goLimits
+	mate := 0
+
+	for i := 0; i < len(args); i++ {
+		name := args[i]
+		if name == "infinite" {
+			limits.infinite = true
+			continue
+		}
+		if name == "searchmoves" {
+			for i+1 < len(args) && !goParameters[args[i+1]] {
+				i++
+			}
+			continue
+		}
+		if !goParameters[name] || name == "ponder" {
+			continue
+		}
+
+		if i+1 >= len(args) {
+			return limits, fmt.Errorf("missing value for go %s", name)
+		}
+		i++
+		value, err := strconv.Atoi(args[i])
+		if err != nil {
+			return limits, fmt.Errorf("invalid value for go %s: %s", name, args[i])
+		}
+		millis := time.Duration(value) * time.Millisecond
+
+		switch name {
+		case "depth":
+			limits.depth = value
+		case "nodes":
+			limits.nodes = uint64(value)
+		case "movetime":
+			limits.moveTime = millis
+		case "wtime":
+			limits.wtime = millis
+		case "btime":
+			limits.btime = millis
+		case "winc":
+			limits.winc = millis
+		case "binc":
+			limits.binc = millis
+		case "movestogo":
+			limits.movesToGo = value
+		case "mate":
+			mate = value
+		}
+	}
+
+	if mate > 0 && limits.depth <= 0 {
+		limits.depth = 2*mate - 1
+	}
+
+	return limits, nil
+}
+
+func (u *uci) startSearch(limits goLimits) {
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	u.stop, u.done = stop, done
+
+	position := u.game.Position()
+
+	go func() {
+		defer close(done)
+
+		best := u.think(position, limits, stop)
+
+		// in infinite mode the best move may only be sent after stop
+		if limits.infinite {
+			<-stop
+		}
+
+		if best == (board.Move{}) {
+			u.send("bestmove 0000")
+		} else {
+			u.send("bestmove %s", best.UCI())
+		}
+	}()
+}
+
+func (u *uci) stopSearch() {
+	if u.stop == nil {
+		return
+	}
+	close(u.stop)
+	<-u.done
+	u.stop, u.done = nil, nil
+}
+
+func (u *uci) think(position board.BitBoard, limits goLimits, stop <-chan struct{}) board.Move {
+	searchLimits := search.Limits{Depth: limits.depth, Nodes: limits.nodes}
+	if !limits.infinite {
+		searchLimits.MoveTime = limits.timeForMove(position.IsWhitesTurn())
+	}
+
+	u.searcher.OnIteration = func(result search.Result) {
+		u.send("%s", infoLine(result))
+	}
+
+	return u.searcher.Search(position, searchLimits, stop).Move
+}
+
+func infoLine(result search.Result) string {
+	score := fmt.Sprintf("cp %d", result.Score)
+	if search.IsMateScore(result.Score) {
+		score = fmt.Sprintf("mate %d", search.MateIn(result.Score))
+	}
+
+	nps := uint64(0)
+	if result.Time > 0 {
+		nps = uint64(float64(result.Nodes) / result.Time.Seconds())
+	}
+
+	pv := make([]string, len(result.PV))
+	for i, move := range result.PV {
+		pv[i] = move.UCI()
+	}
+
+	return fmt.Sprintf("info depth %d score %s nodes %d nps %d hashfull %d time %d pv %s",
+		result.Depth, score, result.Nodes, nps, result.Hashfull, result.Time.Milliseconds(), strings.Join(pv, " "))
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"terrible_chess_computer/board"
+	"terrible_chess_computer/search"
+)
+
+func runUCI(input string) (*uci, string) {
+	var output bytes.Buffer
+	u := newUCI(&output)
+	u.run(strings.NewReader(input))
+	return u, output.String()
+}
+
+func TestUCI_Handshake(t *testing.T) {
+	_, output := runUCI("uci\nisready\nquit\n")
+
+	if !strings.HasPrefix(output, "id name tce\n") {
+		t.Errorf("missing engine name:\n%s", output)
+	}
+	if !strings.Contains(output, "uciok\nreadyok\n") {
+		t.Errorf("missing uciok or readyok:\n%s", output)
+	}
+}
+
+func TestUCI_Position(t *testing.T) {
+	u, _ := runUCI("position startpos moves e2e4 e7e5 g1f3\n")
+	position := u.game.Position()
+	if position.ToFEN() != "rnbqkbnr/pppp1ppp/8/4p3/4P3/5N2/PPPP1PPP/RNBQKB1R b KQkq - 1 2" {
+		t.Errorf("wrong position: %s", position.ToFEN())
+	}
+
+	u, _ = runUCI("position fen 4k3/1P6/8/8/8/8/8/4K2R w K - 0 1 moves e1g1 e8d7 b7b8q\n")
+	position = u.game.Position()
+	if position.ToFEN() != "1Q6/3k4/8/8/8/8/8/5RK1 b - - 0 2" {
+		t.Errorf("wrong position: %s", position.ToFEN())
+	}
+
+	u, output := runUCI("position startpos moves e2e5\n")
+	position = u.game.Position()
+	if !strings.Contains(output, "info string illegal move e2e5") || u.game.Ply() != 0 {
+		t.Errorf("illegal move should be reported and ignored:\n%s", output)
+	}
+
+	_, output = runUCI("position fen 8/8/8 w - - 0 1\n")
+	if !strings.HasPrefix(output, "info string ") {
+		t.Errorf("invalid fen should be reported:\n%s", output)
+	}
+}
+
+func TestUCI_Go(t *testing.T) {
+	_, output := runUCI("position startpos\ngo depth 1\n")
+	if !strings.Contains(output, "bestmove ") {
+		t.Errorf("no best move:\n%s", output)
+	}
+
+	_, output = runUCI("position fen 7k/5Q2/6K1/8/8/8/8/8 b - - 0 1\ngo movetime 10\n")
+	if !strings.Contains(output, "bestmove 0000") {
+		t.Errorf("stalemated side has no move:\n%s", output)
+	}
+
+	_, output = runUCI("go infinite\nisready\nstop\n")
+	if strings.Index(output, "bestmove ") < strings.Index(output, "readyok") {
+		t.Errorf("best move must wait for stop in infinite mode:\n%s", output)
+	}
+}
+
+func TestUCI_SetOption(t *testing.T) {
+	_, output := runUCI("uci\nsetoption name Hash value 1\n")
+	if !strings.Contains(output, "option name Hash type spin default 16 min 1 max 4096\n") {
+		t.Errorf("hash option is not announced:\n%s", output)
+	}
+	if strings.Contains(output, "info string") {
+		t.Errorf("setting the hash size failed:\n%s", output)
+	}
+
+	_, output = runUCI("setoption name Hash value 0\nsetoption name Ponder value true\n")
+	if strings.Count(output, "info string") != 2 {
+		t.Errorf("invalid options should be reported:\n%s", output)
+	}
+}
+
+func TestUCI_GoFindsMate(t *testing.T) {
+	_, output := runUCI("position fen 6k1/5ppp/8/8/8/8/8/R5K1 w - - 0 1\ngo depth 3\n")
+	if !strings.Contains(output, "score mate 1 ") || !strings.HasSuffix(output, "bestmove a1a8\n") {
+		t.Errorf("expected mate in one:\n%s", output)
+	}
+}
+
+func TestInfoLine(t *testing.T) {
+	result := search.Result{
+		Score: -search.MATE + 4,
+		Depth: 4,
+		Nodes: 3000,
+		Time:  1500 * time.Millisecond,
+		PV:    []board.Move{board.NewMove(4, 1, 4, 3)},
+
+		Hashfull: 12,
+	}
+	if line := infoLine(result); line != "info depth 4 score mate -2 nodes 3000 nps 2000 hashfull 12 time 1500 pv e2e4" {
+		t.Errorf("wrong info line: %s", line)
+	}
+
+	result.Score = 35
+	if line := infoLine(result); !strings.HasPrefix(line, "info depth 4 score cp 35 ") {
+		t.Errorf("wrong info line: %s", line)
+	}
+}
+
+func TestParseGo(t *testing.T) {
+	limits, err := parseGo(strings.Fields("wtime 60000 btime 30000 winc 1000 binc 500 movestogo 20"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if limits.wtime != time.Minute || limits.binc != 500*time.Millisecond || limits.movesToGo != 20 {
+		t.Errorf("wrong limits: %+v", limits)
+	}
+	if limits.timeForMove(true) != 3750*time.Millisecond || limits.timeForMove(false) != 1875*time.Millisecond {
+		t.Errorf("wrong time allocation: %v %v", limits.timeForMove(true), limits.timeForMove(false))
+	}
+
+	limits, _ = parseGo(strings.Fields("movetime 250 depth 5 nodes 1000"))
+	if limits.timeForMove(true) != 250*time.Millisecond || limits.depth != 5 || limits.nodes != 1000 {
+		t.Errorf("wrong limits: %+v", limits)
+	}
+
+	limits, err = parseGo(strings.Fields("ponder searchmoves e2e4 d2d4 mate 3 colour wtime 1000"))
+	if err != nil || limits.depth != 5 || limits.wtime != time.Second {
+		t.Errorf("unknown parameters should be skipped: %+v, %v", limits, err)
+	}
+
+	for _, args := range []string{"depth", "depth x"} {
+		if _, err := parseGo(strings.Fields(args)); err == nil {
+			t.Errorf("go %s should be rejected", args)
+		}
+	}
+}
+
+func TestTimeForMove(t *testing.T) {
+	limits := goLimits{btime: time.Second}
+	if limits.timeForMove(true) != fallbackMoveTime {
+		t.Errorf("missing own clock should use the fallback, got %v", limits.timeForMove(true))
+	}
+	if (goLimits{}).timeForMove(true) != 0 {
+		t.Error("no time control should give no time limit")
+	}
+}
+
+func TestUCI_GoMate(t *testing.T) {
+	_, output := runUCI("position fen 6k1/5ppp/8/8/8/8/8/R5K1 w - - 0 1\ngo mate 3\n")
+	if !strings.HasSuffix(output, "bestmove a1a8\n") {
+		t.Errorf("go mate should search and send the mate:\n%s", output)
+	}
+
+	_, output = runUCI("position startpos\ngo depth x\n")
+	if !strings.Contains(output, "info string ") || !strings.Contains(output, "bestmove ") {
+		t.Errorf("invalid go should still send a best move:\n%s", output)
+	}
+}
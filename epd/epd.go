@@ -0,0 +1,177 @@
+package epd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"terrible_chess_computer/board"
+)
+
+// Record is one line of an EPD file, the first four fields of a FEN followed by operations like
+// bm Qxf7+; id "WAC.001";
+type Record struct {
+	Position board.BitBoard
+	ID       string
+	// the c0 comment
+	Comment string
+	// the moves of the bm and am operations, a search solves the record by playing one of the best moves and none
+	// of the avoided ones
+	BestMoves  []board.Move
+	AvoidMoves []board.Move
+	// the operands of every operation by opcode, quotes removed
+	Operations map[string][]string
+}
+
+// Parse reads one EPD record, the halfmove clock and turn are taken from the hmvc and fmvn operations if present
+func Parse(line string) (Record, error) {
+	record := Record{Operations: map[string][]string{}}
+
+	fields := strings.Fields(line)
+	if len(fields) < 4 {
+		return record, fmt.Errorf("epd needs 4 fields, got %d in %q", len(fields), line)
+	}
+
+	// skip the four fields, the rest are the operations
+	rest := line
+	for _, field := range fields[:4] {
+		rest = strings.TrimLeft(rest, " \t")[len(field):]
+	}
+
+	operations, err := splitOperations(rest)
+	if err != nil {
+		return record, err
+	}
+	for _, operation := range operations {
+		record.Operations[operation[0]] = operation[1:]
+	}
+
+	halfmove, turn := "0", "1"
+	if operands := record.Operations["hmvc"]; len(operands) == 1 {
+		halfmove = operands[0]
+	}
+	if operands := record.Operations["fmvn"]; len(operands) == 1 {
+		turn = operands[0]
+	}
+	record.Position, err = board.ParseFEN(strings.Join(append(fields[:4:4], halfmove, turn), " "))
+	if err != nil {
+		return record, err
+	}
+
+	if operands := record.Operations["id"]; len(operands) > 0 {
+		record.ID = operands[0]
+	}
+	if operands := record.Operations["c0"]; len(operands) > 0 {
+		record.Comment = operands[0]
+	}
+	if record.BestMoves, err = record.parseMoves("bm"); err != nil {
+		return record, err
+	}
+	if record.AvoidMoves, err = record.parseMoves("am"); err != nil {
+		return record, err
+	}
+
+	return record, nil
+}
+
+func (record *Record) parseMoves(opcode string) ([]board.Move, error) {
+	var moves []board.Move
+	for _, san := range record.Operations[opcode] {
+		move, err := record.Position.ParseSAN(san)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", opcode, err)
+		}
+		moves = append(moves, move)
+	}
+	return moves, nil
+}
+
+// splitOperations splits `bm Nf3 Ng5; id "a; b";` into opcodes and operands, a ; inside quotes doesn't end an
+// operation and the last ; may be missing
+func splitOperations(text string) ([][]string, error) {
+	var operations [][]string
+	var operation []string
+	var operand strings.Builder
+	quoted, inOperand := false, false
+
+	endOperand := func() {
+		if inOperand {
+			operation = append(operation, operand.String())
+			operand.Reset()
+			inOperand = false
+		}
+	}
+
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case quoted && c == '"':
+			quoted = false
+		case quoted:
+			operand.WriteByte(c)
+		case c == '"':
+			quoted, inOperand = true, true
+		case c == ';':
+			endOperand()
+			if len(operation) > 0 {
+				operations = append(operations, operation)
+			}
+			operation = nil
+		case c == ' ' || c == '\t':
+			endOperand()
+		default:
+			operand.WriteByte(c)
+			inOperand = true
+		}
+	}
+	if quoted {
+		return nil, fmt.Errorf("string is not closed in %q", text)
+	}
+	endOperand()
+	if len(operation) > 0 {
+		operations = append(operations, operation)
+	}
+
+	return operations, nil
+}
+
+// Solved reports whether move is one of the best moves and none of the moves to avoid
+func (record *Record) Solved(move board.Move) bool {
+	for _, avoid := range record.AvoidMoves {
+		if sameMove(avoid, move) {
+			return false
+		}
+	}
+	if len(record.BestMoves) == 0 {
+		return len(record.AvoidMoves) > 0
+	}
+	for _, best := range record.BestMoves {
+		if sameMove(best, move) {
+			return true
+		}
+	}
+	return false
+}
+
+func sameMove(a, b board.Move) bool {
+	return a.FromX == b.FromX && a.FromY == b.FromY && a.ToX == b.ToX && a.ToY == b.ToY && a.Promotion == b.Promotion
+}
+
+// ReadAll parses every record of r, empty lines and lines starting with # are skipped
+func ReadAll(r io.Reader) ([]Record, error) {
+	var records []Record
+	scanner := bufio.NewScanner(r)
+	for number := 1; scanner.Scan(); number++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		record, err := Parse(line)
+		if err != nil {
+			return records, fmt.Errorf("line %d: %v", number, err)
+		}
+		records = append(records, record)
+	}
+	return records, scanner.Err()
+}
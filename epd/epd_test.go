@@ -0,0 +1,76 @@
+package epd
+
+import (
+	"strings"
+	"testing"
+
+	"terrible_chess_computer/board"
+)
+
+func TestParse(t *testing.T) {
+	record, err := Parse(`2rr3k/pp3pp1/1nnqbN1p/3pN3/2pP4/2P3Q1/PPB4P/R4RK1 w - - bm Qg6; id "WAC.001"; c0 "mate; in 3"; hmvc 4; fmvn 20`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if record.ID != "WAC.001" || record.Comment != "mate; in 3" {
+		t.Errorf("wrong id or comment: %q, %q", record.ID, record.Comment)
+	}
+	if record.Position.ToFEN() != "2rr3k/pp3pp1/1nnqbN1p/3pN3/2pP4/2P3Q1/PPB4P/R4RK1 w - - 4 20" {
+		t.Errorf("wrong position: %s", record.Position.ToFEN())
+	}
+	if len(record.BestMoves) != 1 || record.BestMoves[0].String() != "g3g6" {
+		t.Errorf("wrong best moves: %v", record.BestMoves)
+	}
+	if !record.Solved(board.NewMove(6, 2, 6, 5)) || record.Solved(board.NewMove(6, 2, 6, 3)) {
+		t.Error("only Qg6 should solve the record")
+	}
+}
+
+func TestParse_avoidMoves(t *testing.T) {
+	record, err := Parse("4k3/8/8/8/8/8/4P3/4K3 w - - am e3 Kd1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(record.AvoidMoves) != 2 || len(record.Operations["am"]) != 2 {
+		t.Fatalf("expected 2 moves to avoid, got %v", record.AvoidMoves)
+	}
+	if record.Solved(board.NewMove(4, 1, 4, 2)) || !record.Solved(board.NewMove(4, 1, 4, 3)) {
+		t.Error("any move but the avoided ones should solve the record")
+	}
+	if record.Position.GetHalfmove() != 0 || record.Position.GetTurn() != 1 {
+		t.Error("missing hmvc and fmvn should give 0 and 1")
+	}
+}
+
+func TestParse_errors(t *testing.T) {
+	inputs := []string{
+		"4k3/8/8/8/8/8/4P3/4K3 w -",
+		"4k3/8/8/8/8/8/4P3/4K3 w - - bm e5;",
+		"4k3/8/8/8/8/8/4P3/4K3 w - - id \"open;",
+		"4k3/8/8/8/8/8/4P3/4K3 x - - bm e4;",
+	}
+	for _, input := range inputs {
+		if _, err := Parse(input); err == nil {
+			t.Error("expected an error for " + input)
+		}
+	}
+}
+
+func TestReadAll(t *testing.T) {
+	input := "# a comment\n\n" +
+		"4k3/8/8/8/8/8/4P3/4K3 w - - bm e4; id \"a\";\n" +
+		"4k3/8/8/8/8/8/4P3/4K3 b - - bm Kd7; id \"b\";\n"
+	records, err := ReadAll(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 || records[0].ID != "a" || records[1].ID != "b" {
+		t.Error("records aren't read correctly")
+	}
+
+	_, err = ReadAll(strings.NewReader(input + "4k3/8/8/8/8/8/4P3/4K3 w - - bm e5;\n"))
+	if err == nil || !strings.HasPrefix(err.Error(), "line 5:") {
+		t.Errorf("error should name the line, got %v", err)
+	}
+}
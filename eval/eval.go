@@ -0,0 +1,167 @@
+package eval
+
+import (
+	"math/bits"
+
+	"terrible_chess_computer/board"
+)
+
+// indexed by piece type, in the order of the board.Piece constants: pawn, rook, knight, bishop, queen, king
+var middlegameValues = [6]int{100, 500, 320, 330, 900, 0}
+var endgameValues = [6]int{120, 530, 300, 320, 940, 0}
+
+// how much every piece type counts towards the middlegame, 24 with all pieces on the board
+var phaseWeights = [6]int{0, 2, 1, 1, 4, 0}
+
+const maxPhase = 24
+
+// every field around the king the opponent attacks costs this much in the middlegame
+const kingZoneAttackPenalty = 8
+
+// tables are written from white's point of view with rank 8 on top, like looking at a diagram
+var pawnMiddlegame = [64]int{
+	0, 0, 0, 0, 0, 0, 0, 0,
+	50, 50, 50, 50, 50, 50, 50, 50,
+	10, 10, 20, 30, 30, 20, 10, 10,
+	5, 5, 10, 25, 25, 10, 5, 5,
+	0, 0, 0, 20, 20, 0, 0, 0,
+	5, -5, -10, 0, 0, -10, -5, 5,
+	5, 10, 10, -20, -20, 10, 10, 5,
+	0, 0, 0, 0, 0, 0, 0, 0,
+}
+
+var pawnEndgame = [64]int{
+	0, 0, 0, 0, 0, 0, 0, 0,
+	80, 80, 80, 80, 80, 80, 80, 80,
+	50, 50, 50, 50, 50, 50, 50, 50,
+	30, 30, 30, 30, 30, 30, 30, 30,
+	20, 20, 20, 20, 20, 20, 20, 20,
+	10, 10, 10, 10, 10, 10, 10, 10,
+	10, 10, 10, 10, 10, 10, 10, 10,
+	0, 0, 0, 0, 0, 0, 0, 0,
+}
+
+var knightTable = [64]int{
+	-50, -40, -30, -30, -30, -30, -40, -50,
+	-40, -20, 0, 0, 0, 0, -20, -40,
+	-30, 0, 10, 15, 15, 10, 0, -30,
+	-30, 5, 15, 20, 20, 15, 5, -30,
+	-30, 0, 15, 20, 20, 15, 0, -30,
+	-30, 5, 10, 15, 15, 10, 5, -30,
+	-40, -20, 0, 5, 5, 0, -20, -40,
+	-50, -40, -30, -30, -30, -30, -40, -50,
+}
+
+var bishopTable = [64]int{
+	-20, -10, -10, -10, -10, -10, -10, -20,
+	-10, 0, 0, 0, 0, 0, 0, -10,
+	-10, 0, 5, 10, 10, 5, 0, -10,
+	-10, 5, 5, 10, 10, 5, 5, -10,
+	-10, 0, 10, 10, 10, 10, 0, -10,
+	-10, 10, 10, 10, 10, 10, 10, -10,
+	-10, 5, 0, 0, 0, 0, 5, -10,
+	-20, -10, -10, -10, -10, -10, -10, -20,
+}
+
+var rookTable = [64]int{
+	0, 0, 0, 0, 0, 0, 0, 0,
+	5, 10, 10, 10, 10, 10, 10, 5,
+	-5, 0, 0, 0, 0, 0, 0, -5,
+	-5, 0, 0, 0, 0, 0, 0, -5,
+	-5, 0, 0, 0, 0, 0, 0, -5,
+	-5, 0, 0, 0, 0, 0, 0, -5,
+	-5, 0, 0, 0, 0, 0, 0, -5,
+	0, 0, 0, 5, 5, 0, 0, 0,
+}
+
+var queenTable = [64]int{
+	-20, -10, -10, -5, -5, -10, -10, -20,
+	-10, 0, 0, 0, 0, 0, 0, -10,
+	-10, 0, 5, 5, 5, 5, 0, -10,
+	-5, 0, 5, 5, 5, 5, 0, -5,
+	-5, 0, 5, 5, 5, 5, 0, -5,
+	-10, 0, 5, 5, 5, 5, 0, -10,
+	-10, 0, 0, 0, 0, 0, 0, -10,
+	-20, -10, -10, -5, -5, -10, -10, -20,
+}
+
+var kingMiddlegame = [64]int{
+	-30, -40, -40, -50, -50, -40, -40, -30,
+	-30, -40, -40, -50, -50, -40, -40, -30,
+	-30, -40, -40, -50, -50, -40, -40, -30,
+	-30, -40, -40, -50, -50, -40, -40, -30,
+	-20, -30, -30, -40, -40, -30, -30, -20,
+	-10, -20, -20, -20, -20, -20, -20, -10,
+	20, 20, 0, 0, 0, 0, 20, 20,
+	20, 30, 10, 0, 0, 10, 30, 20,
+}
+
+var kingEndgame = [64]int{
+	-50, -40, -30, -20, -20, -30, -40, -50,
+	-30, -20, -10, 0, 0, -10, -20, -30,
+	-30, -10, 20, 30, 30, 20, -10, -30,
+	-30, -10, 30, 40, 40, 30, -10, -30,
+	-30, -10, 30, 40, 40, 30, -10, -30,
+	-30, -10, 20, 30, 30, 20, -10, -30,
+	-30, -30, 0, 0, 0, 0, -30, -30,
+	-50, -30, -30, -30, -30, -30, -30, -50,
+}
+
+var middlegameTables = [6]*[64]int{&pawnMiddlegame, &rookTable, &knightTable, &bishopTable, &queenTable, &kingMiddlegame}
+var endgameTables = [6]*[64]int{&pawnEndgame, &rookTable, &knightTable, &bishopTable, &queenTable, &kingEndgame}
+
+// PieceValue is the middlegame material value of piece in centipawns, 0 for kings and NO_PIECE
+func PieceValue(piece board.Piece) int {
+	if piece.IsNone() {
+		return 0
+	}
+	return middlegameValues[piece%6]
+}
+
+// Evaluate scores position in centipawns from the view of the side to move
+func Evaluate(position *board.BitBoard) int {
+	middlegame, endgame, phase := 0, 0, 0
+
+	for piece := board.BLACK_PAWN; piece <= board.WHITE_KING; piece++ {
+		kind := piece % 6
+		for pieces := position.GetPieces(piece); pieces != 0; pieces &= pieces - 1 {
+			square := bits.TrailingZeros64(pieces)
+			x, y := square%8, square/8
+
+			// flip the rank for white since the tables have rank 8 first
+			index := y*8 + x
+			if piece.IsWhite() {
+				index = (7-y)*8 + x
+			}
+
+			mg := middlegameValues[kind] + middlegameTables[kind][index]
+			eg := endgameValues[kind] + endgameTables[kind][index]
+			if piece.IsWhite() {
+				middlegame += mg
+				endgame += eg
+			} else {
+				middlegame -= mg
+				endgame -= eg
+			}
+			phase += phaseWeights[kind]
+		}
+	}
+
+	middlegame += kingSafety(position, false) - kingSafety(position, true)
+
+	if phase > maxPhase {
+		phase = maxPhase
+	}
+	score := (middlegame*phase + endgame*(maxPhase-phase)) / maxPhase
+
+	if !position.IsWhitesTurn() {
+		return -score
+	}
+	return score
+}
+
+// kingSafety is the penalty for the king of the given side, from the fields around it the opponent attacks
+func kingSafety(position *board.BitBoard, white bool) int {
+	attacked := position.KingZone(white) & position.AttackedSquares(!white)
+	return bits.OnesCount64(attacked) * kingZoneAttackPenalty
+}
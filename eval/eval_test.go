@@ -0,0 +1,127 @@
+package eval
+
+import (
+	"strings"
+	"testing"
+
+	"terrible_chess_computer/board"
+)
+
+var positions = []string{
+	"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+	"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+	"8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1",
+	"r4rk1/1pp1qppp/p1np1n2/2b1p1B1/2B1P1b1/P1NP1N2/1PP1QPPP/R4RK1 w - - 0 10",
+	"4k3/8/8/3q4/8/8/3R4/4K3 w - - 0 1",
+	"8/8/4k3/8/2P5/8/5K2/8 b - - 0 1",
+}
+
+// mirror flips the board vertically and swaps the colors of all pieces, the side to move is kept
+func mirror(fen string) string {
+	fields := strings.Fields(fen)
+
+	ranks := strings.Split(fields[0], "/")
+	for i, j := 0, len(ranks)-1; i < j; i, j = i+1, j-1 {
+		ranks[i], ranks[j] = ranks[j], ranks[i]
+	}
+	fields[0] = swapCase(strings.Join(ranks, "/"))
+
+	if fields[2] != "-" {
+		castleRights := swapCase(fields[2])
+		fields[2] = ""
+		for _, c := range "KQkq" {
+			if strings.ContainsRune(castleRights, c) {
+				fields[2] += string(c)
+			}
+		}
+	}
+
+	return strings.Join(fields, " ")
+}
+
+func swapCase(s string) string {
+	return strings.Map(func(c rune) rune {
+		if c >= 'a' && c <= 'z' {
+			return c - 'a' + 'A'
+		}
+		if c >= 'A' && c <= 'Z' {
+			return c - 'A' + 'a'
+		}
+		return c
+	}, s)
+}
+
+func TestEvaluate_StartPosition(t *testing.T) {
+	position := board.GetStartBoard()
+	if score := Evaluate(&position); score != 0 {
+		t.Errorf("start position is balanced, got %d", score)
+	}
+}
+
+func TestEvaluate_Mirrored(t *testing.T) {
+	for _, fen := range positions {
+		position := board.FromFEN(fen)
+		mirrored := board.FromFEN(mirror(fen))
+
+		score := Evaluate(&position)
+		if mirroredScore := Evaluate(&mirrored); mirroredScore != -score {
+			t.Errorf("mirrored position should score %d, got %d for %s", -score, mirroredScore, mirror(fen))
+		}
+
+		// with the side to move swapped as well it's the same position from the other side
+		mirrored.SetWhitesTurn(!mirrored.IsWhitesTurn())
+		if mirroredScore := Evaluate(&mirrored); mirroredScore != score {
+			t.Errorf("mirrored position with swapped side should score %d, got %d", score, mirroredScore)
+		}
+	}
+}
+
+func TestEvaluate_SideToMove(t *testing.T) {
+	position := board.FromFEN("4k3/8/8/3q4/8/8/3R4/4K3 w - - 0 1")
+	white := Evaluate(&position)
+	position.SetWhitesTurn(false)
+	black := Evaluate(&position)
+
+	if white >= 0 || black != -white {
+		t.Errorf("black is a queen for a rook up, got %d for white and %d for black", white, black)
+	}
+
+	if Evaluate(&position) != black {
+		t.Error("evaluation is not deterministic")
+	}
+}
+
+func TestEvaluate_Tapered(t *testing.T) {
+	// the endgame tables want the king in the center, the middlegame ones want it hidden
+	central := board.FromFEN("4k3/pppppppp/8/8/4K3/8/PPPPPPPP/8 w - - 0 1")
+	home := board.FromFEN("4k3/pppppppp/8/8/8/8/PPPPPPPP/6K1 w - - 0 1")
+	if Evaluate(&central) <= Evaluate(&home) {
+		t.Error("in a pawn ending the king belongs in the center")
+	}
+
+	central = board.FromFEN("rnbqkbnr/pppppppp/8/8/4K3/8/PPPPPPPP/RNBQ1BNR w kq - 0 1")
+	home = board.FromFEN("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w kq - 0 1")
+	if Evaluate(&central) >= Evaluate(&home) {
+		t.Error("with all pieces on the board the king should stay home")
+	}
+}
+
+func TestKingSafety(t *testing.T) {
+	position := board.GetStartBoard()
+	if kingSafety(&position, true) != 0 || kingSafety(&position, false) != 0 {
+		t.Error("no king is attacked in the start position")
+	}
+
+	// the queen on h4 hits f2, h2 and h1, the rook on the e-file is too far away
+	position = board.FromFEN("4r1k1/8/8/8/7q/8/5P2/6K1 w - - 0 1")
+	if safety := kingSafety(&position, true); safety != 3*kingZoneAttackPenalty {
+		t.Errorf("expected three attacked fields, got %d", safety/kingZoneAttackPenalty)
+	}
+}
+
+func TestPieceValue(t *testing.T) {
+	if PieceValue(board.WHITE_QUEEN) != 900 || PieceValue(board.BLACK_PAWN) != 100 ||
+		PieceValue(board.WHITE_KING) != 0 || PieceValue(board.NO_PIECE) != 0 {
+		t.Error("wrong piece values")
+	}
+}
@@ -0,0 +1,157 @@
+package game
+
+import (
+	"errors"
+	"fmt"
+
+	"terrible_chess_computer/board"
+)
+
+// Game keeps every position of a game, positions[i+1] is the result of playing moves[i] in positions[i]
+type Game struct {
+	positions []board.BitBoard
+	moves     []board.Move
+	// tags in the order they were set, a PGN export puts the Seven Tag Roster first
+	tags []Tag
+	// comments[ply] follows the move that leads to ply, comments[0] comes before the first move
+	comments map[int]string
+	result   Result
+}
+
+type Tag struct {
+	Name  string
+	Value string
+}
+
+func InitGame() *Game {
+	return InitGameFromPosition(board.GetStartBoard())
+}
+
+func InitGameFromPosition(position board.BitBoard) *Game {
+	game := &Game{}
+	game.push(position)
+	return game
+}
+
+func (game *Game) push(position board.BitBoard) {
+	game.positions = append(game.positions, position)
+}
+
+func (game *Game) pop() board.BitBoard {
+	position := game.positions[len(game.positions)-1]
+	game.positions = game.positions[:len(game.positions)-1]
+	return position
+}
+
+func (game *Game) Position() board.BitBoard {
+	return game.positions[len(game.positions)-1]
+}
+
+func (game *Game) StartPosition() board.BitBoard {
+	return game.positions[0]
+}
+
+func (game *Game) Moves() []board.Move {
+	moves := make([]board.Move, len(game.moves))
+	copy(moves, game.moves)
+	return moves
+}
+
+func (game *Game) Positions() []board.BitBoard {
+	positions := make([]board.BitBoard, len(game.positions))
+	copy(positions, game.positions)
+	return positions
+}
+
+func (game *Game) Ply() int {
+	return len(game.moves)
+}
+
+// MakeMove plays move if it is legal in the current position, flags of move don't need to be set
+func (game *Game) MakeMove(move board.Move) error {
+	position := game.Position()
+
+	for _, legalMove := range position.LegalMoves() {
+		if legalMove.FromX == move.FromX && legalMove.FromY == move.FromY &&
+			legalMove.ToX == move.ToX && legalMove.ToY == move.ToY && legalMove.Promotion == move.Promotion {
+			position.MakeMove(legalMove)
+			game.push(position)
+			game.moves = append(game.moves, legalMove)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("illegal move %s", move)
+}
+
+func (game *Game) TakeBack() (board.Move, error) {
+	if len(game.moves) == 0 {
+		return board.Move{}, errors.New("no move to take back")
+	}
+
+	game.pop()
+	delete(game.comments, len(game.moves))
+	move := game.moves[len(game.moves)-1]
+	game.moves = game.moves[:len(game.moves)-1]
+	return move, nil
+}
+
+// SetTag adds the tag or replaces its value if it is already set
+func (game *Game) SetTag(name, value string) {
+	for i := range game.tags {
+		if game.tags[i].Name == name {
+			game.tags[i].Value = value
+			return
+		}
+	}
+	game.tags = append(game.tags, Tag{name, value})
+}
+
+// GetTag returns the value of the tag name or "" if it isn't set
+func (game *Game) GetTag(name string) string {
+	for _, tag := range game.tags {
+		if tag.Name == name {
+			return tag.Value
+		}
+	}
+	return ""
+}
+
+func (game *Game) Tags() []Tag {
+	tags := make([]Tag, len(game.tags))
+	copy(tags, game.tags)
+	return tags
+}
+
+// SetComment sets the comment after the move that leads to ply, ply 0 is before the first move
+func (game *Game) SetComment(ply int, comment string) error {
+	if ply < 0 || ply > game.Ply() {
+		return fmt.Errorf("no ply %d in a game of %d plies", ply, game.Ply())
+	}
+	if comment == "" {
+		delete(game.comments, ply)
+		return nil
+	}
+	if game.comments == nil {
+		game.comments = map[int]string{}
+	}
+	game.comments[ply] = comment
+	return nil
+}
+
+func (game *Game) GetComment(ply int) string {
+	return game.comments[ply]
+}
+
+// SetResult records how the game ended, for games that end by resignation, time or agreement
+func (game *Game) SetResult(result Result) {
+	game.result = result
+}
+
+// GetResult is the result that was set or else the result of the outcome
+func (game *Game) GetResult() Result {
+	if game.result != ONGOING {
+		return game.result
+	}
+	return game.Outcome().Result
+}
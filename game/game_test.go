@@ -1,24 +1,92 @@
-package board
+package game
 
 import (
-    game2 "terrible_chess_computer/game"
-    "testing"
+	"terrible_chess_computer/board"
+	"testing"
 )
 
 func TestGame_pop_push(t *testing.T) {
-	game := game2.Game{}
+	game := Game{}
 
-	board := GetStartBoard()
-	game.push(board)
-	if !board.Equal(game.pop()) {
+	position := board.GetStartBoard()
+	game.push(position)
+	if !position.Equal(game.pop()) {
 		t.Error("popped board not equal to original")
 	}
 }
 
 func Test_InitGame(t *testing.T) {
-	game := game2.InitGame()
-	board := GetStartBoard()
-	if !board.Equal(game.pop()) {
+	game := InitGame()
+	position := board.GetStartBoard()
+	if !position.Equal(game.pop()) {
 		t.Error("game not initialized, position unequal to start position")
 	}
 }
+
+func TestGame_MakeMove(t *testing.T) {
+	game := InitGame()
+
+	if err := game.MakeMove(board.NewMove(4, 1, 4, 3)); err != nil {
+		t.Fatalf("e2e4 should be legal: %v", err)
+	}
+	if err := game.MakeMove(board.NewMove(4, 6, 4, 4)); err != nil {
+		t.Fatalf("e7e5 should be legal: %v", err)
+	}
+
+	position := game.Position()
+	if position.ToFEN() != "rnbqkbnr/pppp1ppp/8/4p3/4P3/8/PPPP1PPP/RNBQKBNR w KQkq e6 0 2" {
+		t.Errorf("wrong position after two moves: %s", position.ToFEN())
+	}
+	if game.Ply() != 2 || len(game.Positions()) != 3 {
+		t.Error("history not recorded")
+	}
+	if !game.Moves()[0].IsDoublePush() {
+		t.Error("recorded move is missing its flags")
+	}
+
+	if err := game.MakeMove(board.NewMove(4, 3, 4, 4)); err == nil {
+		t.Error("pawn can't move onto an occupied field")
+	}
+	if game.Ply() != 2 {
+		t.Error("illegal move has been recorded")
+	}
+}
+
+func TestGame_TakeBack(t *testing.T) {
+	game := InitGame()
+
+	if _, err := game.TakeBack(); err == nil {
+		t.Error("there is no move to take back")
+	}
+
+	game.MakeMove(board.NewMove(6, 0, 5, 2))
+	move, err := game.TakeBack()
+	if err != nil || move != board.NewMove(6, 0, 5, 2) {
+		t.Errorf("wrong move taken back: %s %v", move, err)
+	}
+
+	position := game.Position()
+	start := board.GetStartBoard()
+	if !position.Equal(start) || game.Ply() != 0 {
+		t.Error("take back didn't restore the start position")
+	}
+}
+
+func TestGame_InitGameFromPosition(t *testing.T) {
+	start := board.FromFEN("4k3/8/8/8/8/8/8/4K2R w K - 0 1")
+	game := InitGameFromPosition(start)
+
+	castle := board.NewMove(4, 0, 6, 0)
+	if err := game.MakeMove(castle); err != nil {
+		t.Fatalf("castling should be legal: %v", err)
+	}
+
+	startPosition := game.StartPosition()
+	if !startPosition.Equal(start) {
+		t.Error("start position changed")
+	}
+	position := game.Position()
+	if position.ToFEN() != "4k3/8/8/8/8/8/8/5RK1 b - - 1 1" {
+		t.Errorf("wrong position after castling: %s", position.ToFEN())
+	}
+}
@@ -0,0 +1,108 @@
+package game
+
+type Result int
+
+const (
+	ONGOING Result = iota
+	WHITE_WINS
+	BLACK_WINS
+	DRAW
+)
+
+func (result Result) String() string {
+	symbols := [4]string{"*", "1-0", "0-1", "1/2-1/2"}
+	return symbols[result]
+}
+
+type Reason int
+
+const (
+	NO_REASON Reason = iota
+	CHECKMATE
+	STALEMATE
+	FIFTY_MOVE_RULE
+	SEVENTY_FIVE_MOVE_RULE
+	THREEFOLD_REPETITION
+	FIVEFOLD_REPETITION
+	INSUFFICIENT_MATERIAL
+)
+
+func (reason Reason) String() string {
+	names := [8]string{
+		"", "checkmate", "stalemate", "fifty-move rule", "seventy-five-move rule",
+		"threefold repetition", "fivefold repetition", "insufficient material",
+	}
+	return names[reason]
+}
+
+type Outcome struct {
+	Result Result
+	Reason Reason
+}
+
+func (outcome Outcome) IsOver() bool {
+	return outcome.Result != ONGOING
+}
+
+func (outcome Outcome) String() string {
+	if outcome.Reason == NO_REASON {
+		return outcome.Result.String()
+	}
+	return outcome.Result.String() + " (" + outcome.Reason.String() + ")"
+}
+
+// Repetitions counts how often the current position occurred in the game, itself included
+func (game *Game) Repetitions() int {
+	position := game.Position()
+	count := 0
+
+	// positions before the last capture or pawn move can't come up again
+	for i := len(game.positions) - 1; i >= 0 && i >= len(game.positions)-1-position.GetHalfmove(); i -= 2 {
+		if position.SamePosition(game.positions[i]) {
+			count++
+		}
+	}
+
+	return count
+}
+
+// Outcome reports the first rule that ends the game. Draws by the fifty-move rule and threefold repetition would
+// have to be claimed by a player over the board, they are reported nevertheless.
+func (game *Game) Outcome() Outcome {
+	position := game.Position()
+	white := position.IsWhitesTurn()
+
+	if position.IsCheckMate(white) {
+		if white {
+			return Outcome{BLACK_WINS, CHECKMATE}
+		}
+		return Outcome{WHITE_WINS, CHECKMATE}
+	}
+
+	if position.IsStalemate(white) {
+		return Outcome{DRAW, STALEMATE}
+	}
+
+	repetitions := game.Repetitions()
+	if repetitions >= 5 {
+		return Outcome{DRAW, FIVEFOLD_REPETITION}
+	}
+
+	if position.GetHalfmove() >= 150 {
+		return Outcome{DRAW, SEVENTY_FIVE_MOVE_RULE}
+	}
+
+	if position.IsInsufficientMaterial() {
+		return Outcome{DRAW, INSUFFICIENT_MATERIAL}
+	}
+
+	if repetitions >= 3 {
+		return Outcome{DRAW, THREEFOLD_REPETITION}
+	}
+
+	if position.GetHalfmove() >= 100 {
+		return Outcome{DRAW, FIFTY_MOVE_RULE}
+	}
+
+	return Outcome{ONGOING, NO_REASON}
+}
@@ -0,0 +1,113 @@
+package game
+
+import (
+	"terrible_chess_computer/board"
+	"testing"
+)
+
+func playMoves(t *testing.T, game *Game, moves [][4]int) {
+	for _, m := range moves {
+		if err := game.MakeMove(board.NewMove(m[0], m[1], m[2], m[3])); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestGame_OutcomeOngoing(t *testing.T) {
+	game := InitGame()
+	if outcome := game.Outcome(); outcome.IsOver() || outcome.String() != "*" {
+		t.Errorf("game just started, got %s", outcome)
+	}
+}
+
+func TestGame_OutcomeCheckMate(t *testing.T) {
+	game := InitGame()
+	// fool's mate: f3 e5 g4 Qh4#
+	playMoves(t, game, [][4]int{{5, 1, 5, 2}, {4, 6, 4, 4}, {6, 1, 6, 3}, {3, 7, 7, 3}})
+
+	outcome := game.Outcome()
+	if outcome != (Outcome{BLACK_WINS, CHECKMATE}) {
+		t.Errorf("black should have won by checkmate, got %s", outcome)
+	}
+	if outcome.String() != "0-1 (checkmate)" {
+		t.Errorf("wrong description: %s", outcome)
+	}
+}
+
+func TestGame_OutcomeStalemate(t *testing.T) {
+	game := InitGameFromPosition(board.FromFEN("7k/8/6K1/5Q2/8/8/8/8 w - - 0 1"))
+	playMoves(t, game, [][4]int{{5, 4, 5, 6}})
+
+	if outcome := game.Outcome(); outcome != (Outcome{DRAW, STALEMATE}) {
+		t.Errorf("expected stalemate, got %s", outcome)
+	}
+}
+
+func TestGame_OutcomeRepetition(t *testing.T) {
+	game := InitGame()
+	knightDance := [][4]int{{6, 0, 5, 2}, {6, 7, 5, 5}, {5, 2, 6, 0}, {5, 5, 6, 7}}
+
+	playMoves(t, game, knightDance)
+	if game.Repetitions() != 2 || game.Outcome().IsOver() {
+		t.Error("start position occurred only twice")
+	}
+
+	playMoves(t, game, knightDance)
+	if outcome := game.Outcome(); outcome != (Outcome{DRAW, THREEFOLD_REPETITION}) {
+		t.Errorf("expected threefold repetition, got %s", outcome)
+	}
+
+	playMoves(t, game, knightDance)
+	playMoves(t, game, knightDance)
+	if outcome := game.Outcome(); outcome != (Outcome{DRAW, FIVEFOLD_REPETITION}) {
+		t.Errorf("expected fivefold repetition, got %s", outcome)
+	}
+}
+
+func TestGame_OutcomeRepetitionAfterDoublePush(t *testing.T) {
+	game := InitGame()
+	// 1. e4 Nf6 2. Nf3 Ng8 3. Ng1 Nf6 4. Nf3 Ng8 5. Ng1, no pawn can take on e3 after 1. e4
+	playMoves(t, game, [][4]int{
+		{4, 1, 4, 3}, {6, 7, 5, 5}, {6, 0, 5, 2}, {5, 5, 6, 7}, {5, 2, 6, 0},
+		{6, 7, 5, 5}, {6, 0, 5, 2}, {5, 5, 6, 7}, {5, 2, 6, 0},
+	})
+	if game.Repetitions() != 3 {
+		t.Errorf("position after 1. e4 occurred 3 times, got %d", game.Repetitions())
+	}
+	if outcome := game.Outcome(); outcome != (Outcome{DRAW, THREEFOLD_REPETITION}) {
+		t.Errorf("expected threefold repetition, got %s", outcome)
+	}
+
+	// with a pawn on d4 black could take en passant right after 1... e5, so that position is a different one
+	game = InitGameFromPosition(board.FromFEN("4k1n1/4p3/8/8/3P4/8/8/4K1N1 w - - 0 1"))
+	playMoves(t, game, [][4]int{{3, 3, 3, 4}, {4, 6, 4, 4}, {6, 0, 5, 2}, {6, 7, 5, 5}, {5, 2, 6, 0}, {5, 5, 6, 7}})
+	if game.Repetitions() != 1 {
+		t.Errorf("position with a legal en passant capture shouldn't repeat, got %d", game.Repetitions())
+	}
+}
+
+func TestGame_OutcomeMoveRules(t *testing.T) {
+	game := InitGameFromPosition(board.FromFEN("4k3/8/8/8/8/8/8/R3K3 w - - 99 80"))
+	if game.Outcome().IsOver() {
+		t.Error("only 99 halfmoves without progress")
+	}
+	playMoves(t, game, [][4]int{{0, 0, 0, 1}})
+	if outcome := game.Outcome(); outcome != (Outcome{DRAW, FIFTY_MOVE_RULE}) {
+		t.Errorf("expected fifty-move rule, got %s", outcome)
+	}
+
+	game = InitGameFromPosition(board.FromFEN("4k3/8/8/8/8/8/8/R3K3 b - - 149 100"))
+	playMoves(t, game, [][4]int{{4, 7, 3, 7}})
+	if outcome := game.Outcome(); outcome != (Outcome{DRAW, SEVENTY_FIVE_MOVE_RULE}) {
+		t.Errorf("expected seventy-five-move rule, got %s", outcome)
+	}
+}
+
+func TestGame_OutcomeInsufficientMaterial(t *testing.T) {
+	game := InitGameFromPosition(board.FromFEN("4k3/8/8/8/8/8/3r4/4KB2 w - - 0 1"))
+	playMoves(t, game, [][4]int{{4, 0, 3, 1}})
+
+	if outcome := game.Outcome(); outcome != (Outcome{DRAW, INSUFFICIENT_MATERIAL}) {
+		t.Errorf("expected insufficient material, got %s", outcome)
+	}
+}
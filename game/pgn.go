@@ -0,0 +1,129 @@
+package game
+
+import (
+	"io"
+	"strconv"
+	"strings"
+
+	"terrible_chess_computer/board"
+)
+
+// the Seven Tag Roster and the values of tags that aren't known
+var sevenTagRoster = [7]Tag{
+	{"Event", "?"}, {"Site", "?"}, {"Date", "????.??.??"}, {"Round", "?"}, {"White", "?"}, {"Black", "?"}, {"Result", "*"},
+}
+
+const pgnLineLength = 80
+
+// PGN exports the game with the Seven Tag Roster, the other tags, a FEN and SetUp tag if the game didn't start from
+// the start position, and the movetext with comments and the result
+func (game *Game) PGN() string {
+	var output strings.Builder
+	result := game.GetResult().String()
+
+	for _, tag := range sevenTagRoster {
+		value := game.GetTag(tag.Name)
+		if tag.Name == "Result" {
+			value = result
+		}
+		if value == "" {
+			value = tag.Value
+		}
+		writeTag(&output, tag.Name, value)
+	}
+
+	start := game.StartPosition()
+	startFEN, defaultFEN := start.ToFEN(), board.GetStartBoard()
+	custom := startFEN != defaultFEN.ToFEN()
+	if custom {
+		writeTag(&output, "SetUp", "1")
+		writeTag(&output, "FEN", startFEN)
+	}
+
+	for _, tag := range game.tags {
+		if isRosterTag(tag.Name) || (custom && (tag.Name == "SetUp" || tag.Name == "FEN")) {
+			continue
+		}
+		writeTag(&output, tag.Name, tag.Value)
+	}
+	output.WriteString("\n")
+
+	var words []string
+	words = appendComment(words, game.comments[0])
+
+	turn, white := start.GetTurn(), start.IsWhitesTurn()
+	// black needs a move number after the start and after every comment
+	numbered := false
+	for i, move := range game.moves {
+		position := game.positions[i]
+		if white {
+			words = append(words, strconv.Itoa(turn)+".")
+		} else if !numbered {
+			words = append(words, strconv.Itoa(turn)+"...")
+		}
+		words = append(words, position.ToSAN(move))
+
+		comment := game.comments[i+1]
+		words = appendComment(words, comment)
+		numbered = white && comment == ""
+
+		if !white {
+			turn++
+		}
+		white = !white
+	}
+	words = append(words, result)
+
+	writeWrapped(&output, words)
+	return output.String()
+}
+
+// WritePGN writes the game followed by an empty line, so games can be appended to one file
+func (game *Game) WritePGN(w io.Writer) error {
+	_, err := io.WriteString(w, game.PGN()+"\n")
+	return err
+}
+
+func isRosterTag(name string) bool {
+	for _, tag := range sevenTagRoster {
+		if tag.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func writeTag(output *strings.Builder, name, value string) {
+	value = strings.ReplaceAll(value, "\\", "\\\\")
+	value = strings.ReplaceAll(value, "\"", "\\\"")
+	output.WriteString("[" + name + " \"" + value + "\"]\n")
+}
+
+// appendComment splits the comment into words so long comments can be wrapped, a } would end it early
+func appendComment(words []string, comment string) []string {
+	fields := strings.Fields(strings.ReplaceAll(comment, "}", ")"))
+	if len(fields) == 0 {
+		return words
+	}
+	fields[0] = "{" + fields[0]
+	fields[len(fields)-1] += "}"
+	return append(words, fields...)
+}
+
+// writeWrapped joins words with spaces and starts a new line before a line gets longer than pgnLineLength
+func writeWrapped(output *strings.Builder, words []string) {
+	length := 0
+	for _, word := range words {
+		if length > 0 && length+1+len(word) > pgnLineLength {
+			output.WriteString("\n")
+			length = 0
+		}
+		if length > 0 {
+			output.WriteString(" ")
+			length++
+		}
+		output.WriteString(word)
+		length += len(word)
+	}
+	output.WriteString("\n")
+}
@@ -0,0 +1,109 @@
+package game
+
+import (
+	"strings"
+	"testing"
+
+	"terrible_chess_computer/board"
+)
+
+func TestGame_PGN(t *testing.T) {
+	game := InitGame()
+	game.SetTag("White", "tce")
+	game.SetTag("Black", "Someone \"quoted\"")
+	game.SetTag("TimeControl", "40/600")
+	// fool's mate
+	playMoves(t, game, [][4]int{{5, 1, 5, 2}, {4, 6, 4, 4}, {6, 1, 6, 3}, {3, 7, 7, 3}})
+	if err := game.SetComment(2, "+0.35/12"); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `[Event "?"]
+[Site "?"]
+[Date "????.??.??"]
+[Round "?"]
+[White "tce"]
+[Black "Someone \"quoted\""]
+[Result "0-1"]
+[TimeControl "40/600"]
+
+1. f3 e5 {+0.35/12} 2. g4 Qh4# 0-1
+`
+	if game.PGN() != expected {
+		t.Errorf("wrong PGN:\n%s", game.PGN())
+	}
+}
+
+func TestGame_PGNFromPosition(t *testing.T) {
+	position, err := board.ParseFEN("4k3/8/8/8/8/8/4P3/4K3 b - - 0 12")
+	if err != nil {
+		t.Fatal(err)
+	}
+	game := InitGameFromPosition(position)
+	playMoves(t, game, [][4]int{{4, 7, 3, 6}, {4, 1, 4, 3}})
+	game.SetComment(1, "first")
+	game.SetResult(DRAW)
+
+	pgn := game.PGN()
+	if !strings.Contains(pgn, "[SetUp \"1\"]\n[FEN \"4k3/8/8/8/8/8/4P3/4K3 b - - 0 12\"]\n") {
+		t.Error("FEN and SetUp tags are missing")
+	}
+	if !strings.HasSuffix(pgn, "\n12... Kd7 {first} 13. e4 1/2-1/2\n") {
+		t.Errorf("wrong movetext:\n%s", pgn)
+	}
+	if strings.Contains(InitGame().PGN(), "FEN") {
+		t.Error("games from the start position don't need a FEN tag")
+	}
+}
+
+func TestGame_PGNWrapping(t *testing.T) {
+	game := InitGame()
+	knights := [][4]int{{6, 0, 5, 2}, {6, 7, 5, 5}, {5, 2, 6, 0}, {5, 5, 6, 7}}
+	for i := 0; i < 10; i++ {
+		playMoves(t, game, knights)
+	}
+	game.SetComment(3, strings.Repeat("long comment ", 10))
+
+	pgn := game.PGN()
+	movetext := pgn[strings.Index(pgn, "\n\n")+2:]
+	for _, line := range strings.Split(strings.TrimSuffix(movetext, "\n"), "\n") {
+		if len(line) > 80 || len(line) == 0 {
+			t.Errorf("line should have 1 to 80 characters: %q", line)
+		}
+	}
+	if !strings.Contains(movetext, "2. Ng1 {long") || !strings.Contains(movetext, "comment} 2...") {
+		t.Errorf("comment isn't written correctly:\n%s", movetext)
+	}
+	if !strings.HasSuffix(movetext, " 1/2-1/2\n") {
+		t.Error("repetition should be written as a draw")
+	}
+}
+
+func TestGame_Tags(t *testing.T) {
+	game := InitGame()
+	game.SetTag("Event", "a")
+	game.SetTag("Site", "b")
+	game.SetTag("Event", "c")
+	tags := game.Tags()
+	if len(tags) != 2 || tags[0] != (Tag{"Event", "c"}) || game.GetTag("Site") != "b" {
+		t.Error("setting a tag twice should replace its value")
+	}
+
+	if game.SetComment(1, "no move yet") == nil {
+		t.Error("comment after a move that wasn't played should fail")
+	}
+	playMoves(t, game, [][4]int{{4, 1, 4, 3}})
+	game.SetComment(1, "e4")
+	game.TakeBack()
+	if game.GetComment(1) != "" {
+		t.Error("taking back a move should drop its comment")
+	}
+}
+
+func TestGame_SetCommentZeroValue(t *testing.T) {
+	game := Game{}
+	game.push(board.GetStartBoard())
+	if err := game.SetComment(0, "start"); err != nil || game.GetComment(0) != "start" {
+		t.Error("comment on a zero value game should be set")
+	}
+}
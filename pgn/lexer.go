@@ -0,0 +1,202 @@
+package pgn
+
+import (
+	"fmt"
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	TAG tokenKind = iota
+	COMMENT
+	NAG
+	VARIATION_START
+	VARIATION_END
+	RESULT
+	MOVE
+)
+
+type token struct {
+	kind tokenKind
+	// the tag name, the comment, the NAG number, the result or the move in SAN
+	text string
+	// the value of a tag
+	value string
+	line  int
+}
+
+// lexer splits PGN text into tokens, move numbers are dropped on the way
+type lexer struct {
+	input string
+	pos   int
+	line  int
+}
+
+func newLexer(input string) *lexer {
+	return &lexer{input: input, line: 1}
+}
+
+func (l *lexer) peekByte() byte {
+	if l.pos >= len(l.input) {
+		return 0
+	}
+	return l.input[l.pos]
+}
+
+func (l *lexer) skipLine() {
+	for l.pos < len(l.input) && l.input[l.pos] != '\n' {
+		l.pos++
+	}
+}
+
+// next returns the next token, ok is false at the end of the input
+func (l *lexer) next() (token, bool, error) {
+	for l.pos < len(l.input) {
+		c := l.input[l.pos]
+		switch {
+		case c == '\n':
+			l.line++
+			l.pos++
+			// lines starting with % are escaped from the PGN data
+			if l.peekByte() == '%' {
+				l.skipLine()
+			}
+		case c == ' ' || c == '\t' || c == '\r':
+			l.pos++
+		case c == ';':
+			start := l.pos + 1
+			l.skipLine()
+			return token{kind: COMMENT, text: strings.TrimSpace(l.input[start:l.pos]), line: l.line}, true, nil
+		case c == '{':
+			return l.comment()
+		case c == '[':
+			return l.tag()
+		case c == '(':
+			l.pos++
+			return token{kind: VARIATION_START, line: l.line}, true, nil
+		case c == ')':
+			l.pos++
+			return token{kind: VARIATION_END, line: l.line}, true, nil
+		case c == '$':
+			l.pos++
+			start := l.pos
+			for l.pos < len(l.input) && l.input[l.pos] >= '0' && l.input[l.pos] <= '9' {
+				l.pos++
+			}
+			if start == l.pos {
+				return token{}, false, fmt.Errorf("line %d: NAG without a number", l.line)
+			}
+			return token{kind: NAG, text: l.input[start:l.pos], line: l.line}, true, nil
+		case l.pos == 0 && c == '%':
+			l.skipLine()
+		default:
+			if t, ok := l.symbol(); ok {
+				return t, true, nil
+			}
+		}
+	}
+	return token{}, false, nil
+}
+
+func (l *lexer) comment() (token, bool, error) {
+	line := l.line
+	end := strings.IndexByte(l.input[l.pos:], '}')
+	if end < 0 {
+		return token{}, false, fmt.Errorf("line %d: comment is not closed", line)
+	}
+	text := l.input[l.pos+1 : l.pos+end]
+	l.line += strings.Count(text, "\n")
+	l.pos += end + 1
+	return token{kind: COMMENT, text: strings.Join(strings.Fields(text), " "), line: line}, true, nil
+}
+
+// tag reads [Name "value"], the value may contain \" and \\
+func (l *lexer) tag() (token, bool, error) {
+	line := l.line
+	l.pos++
+	l.skipSpaces()
+
+	start := l.pos
+	for l.pos < len(l.input) && isSymbolChar(l.input[l.pos]) {
+		l.pos++
+	}
+	name := l.input[start:l.pos]
+	if name == "" {
+		return token{}, false, fmt.Errorf("line %d: tag without a name", line)
+	}
+
+	l.skipSpaces()
+	if l.peekByte() != '"' {
+		return token{}, false, fmt.Errorf("line %d: tag %s without a value", line, name)
+	}
+	l.pos++
+
+	var value strings.Builder
+	for {
+		if l.pos >= len(l.input) || l.input[l.pos] == '\n' {
+			return token{}, false, fmt.Errorf("line %d: value of tag %s is not closed", line, name)
+		}
+		c := l.input[l.pos]
+		l.pos++
+		if c == '"' {
+			break
+		}
+		if c == '\\' && l.pos < len(l.input) {
+			c = l.input[l.pos]
+			l.pos++
+		}
+		value.WriteByte(c)
+	}
+
+	l.skipSpaces()
+	if l.peekByte() != ']' {
+		return token{}, false, fmt.Errorf("line %d: tag %s is not closed", line, name)
+	}
+	l.pos++
+
+	return token{kind: TAG, text: name, value: value.String(), line: line}, true, nil
+}
+
+func (l *lexer) skipSpaces() {
+	for l.pos < len(l.input) && (l.input[l.pos] == ' ' || l.input[l.pos] == '\t') {
+		l.pos++
+	}
+}
+
+// symbol reads a move, a result or a move number, move numbers give no token
+func (l *lexer) symbol() (token, bool) {
+	start := l.pos
+	for l.pos < len(l.input) && !strings.ContainsRune(" \t\r\n{}();[]$", rune(l.input[l.pos])) {
+		l.pos++
+	}
+	if start == l.pos {
+		// a character that can't start anything, like a stray ]
+		l.pos++
+	}
+	text := l.input[start:l.pos]
+
+	switch text {
+	case "1-0", "0-1", "1/2-1/2", "*":
+		return token{kind: RESULT, text: text, line: l.line}, true
+	}
+
+	// move numbers like 12. or 12... may be glued to the move
+	digits := 0
+	for digits < len(text) && text[digits] >= '0' && text[digits] <= '9' {
+		digits++
+	}
+	if digits > 0 && digits < len(text) && text[digits] == '.' {
+		text = strings.TrimLeft(text[digits:], ".")
+	}
+	if text == "" || strings.Trim(text, "0123456789") == "" {
+		return token{}, false
+	}
+
+	return token{kind: MOVE, text: text, line: l.line}, true
+}
+
+func isSymbolChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '+' ||
+		c == '#' || c == '=' || c == ':' || c == '-'
+}
@@ -0,0 +1,69 @@
+package pgn
+
+import "testing"
+
+func lexAll(t *testing.T, input string) []token {
+	l := newLexer(input)
+	var tokens []token
+	for {
+		tok, ok, err := l.next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok {
+			return tokens
+		}
+		tokens = append(tokens, tok)
+	}
+}
+
+func TestLexer_tokens(t *testing.T) {
+	input := "[Event \"a \\\"quoted\\\" \\\\ name\"]\n" +
+		"%escaped line\n" +
+		"1.e4 {a\n  comment} e5 $1 2. Nf3 ; line comment\n" +
+		"(2. f4) 2... Nc6 1-0"
+	tokens := lexAll(t, input)
+
+	expected := []token{
+		{kind: TAG, text: "Event", value: "a \"quoted\" \\ name", line: 1},
+		{kind: MOVE, text: "e4", line: 3},
+		{kind: COMMENT, text: "a comment", line: 3},
+		{kind: MOVE, text: "e5", line: 4},
+		{kind: NAG, text: "1", line: 4},
+		{kind: MOVE, text: "Nf3", line: 4},
+		{kind: COMMENT, text: "line comment", line: 4},
+		{kind: VARIATION_START, line: 5},
+		{kind: MOVE, text: "f4", line: 5},
+		{kind: VARIATION_END, line: 5},
+		{kind: MOVE, text: "Nc6", line: 5},
+		{kind: RESULT, text: "1-0", line: 5},
+	}
+	if len(tokens) != len(expected) {
+		t.Fatalf("expected %d tokens, got %d: %v", len(expected), len(tokens), tokens)
+	}
+	for i := range expected {
+		if tokens[i] != expected[i] {
+			t.Errorf("token %d should be %v, got %v", i, expected[i], tokens[i])
+		}
+	}
+}
+
+func TestLexer_errors(t *testing.T) {
+	for _, input := range []string{"{open comment", "[Event \"open", "[Event]", "e4 $ e5"} {
+		l := newLexer(input)
+		failed := false
+		for {
+			_, ok, err := l.next()
+			if err != nil {
+				failed = true
+				break
+			}
+			if !ok {
+				break
+			}
+		}
+		if !failed {
+			t.Error("expected an error for " + input)
+		}
+	}
+}
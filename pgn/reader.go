@@ -0,0 +1,259 @@
+package pgn
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"terrible_chess_computer/board"
+	"terrible_chess_computer/game"
+)
+
+// Game is one game of a PGN file replayed from its start position. Tags and the result are kept in the game.Game,
+// which gets the comments of a ply joined into one for export.
+type Game struct {
+	Game *game.Game
+	// comments of the main line one by one, Comments[ply] follow the move that leads to ply, Comments[0] come before
+	// the first move
+	Comments map[int][]string
+	// NAGs of the main line without the $, kept like the comments
+	NAGs map[int][]string
+}
+
+// Error tells which game of the file failed, and at which ply and move if it was a move
+type Error struct {
+	Game int
+	Line int
+	// 0 if the error isn't about a move
+	Ply  int
+	Move string
+	// move number and side of the move, taken from the position it was tried in
+	Turn  int
+	White bool
+	Err   error
+}
+
+func (err *Error) Error() string {
+	if err.Move == "" {
+		return fmt.Sprintf("game %d, line %d: %v", err.Game, err.Line, err.Err)
+	}
+	dots := "."
+	if !err.White {
+		dots = "..."
+	}
+	return fmt.Sprintf("game %d, line %d, ply %d (%d%s %s): %v", err.Game, err.Line, err.Ply, err.Turn, dots, err.Move, err.Err)
+}
+
+func (err *Error) Unwrap() error {
+	return err.Err
+}
+
+type Reader struct {
+	lexer *lexer
+	// a token read ahead that belongs to the next game
+	pending *token
+	games   int
+}
+
+// NewReader reads the whole input at once, PGN files are small compared to memory
+func NewReader(r io.Reader) (*Reader, error) {
+	input, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return &Reader{lexer: newLexer(string(input))}, nil
+}
+
+// ReadAll parses every game of r and stops at the first error
+func ReadAll(r io.Reader) ([]*Game, error) {
+	reader, err := NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var games []*Game
+	for {
+		g, err := reader.Next()
+		if err == io.EOF {
+			return games, nil
+		}
+		if err != nil {
+			return games, err
+		}
+		games = append(games, g)
+	}
+}
+
+func (r *Reader) nextToken() (token, bool, error) {
+	if r.pending != nil {
+		t := *r.pending
+		r.pending = nil
+		return t, true, nil
+	}
+	return r.lexer.next()
+}
+
+// Next parses the next game and returns io.EOF after the last one. After an error in a game the rest of it is
+// skipped, so the following games can still be read.
+func (r *Reader) Next() (*Game, error) {
+	t, ok, err := r.nextToken()
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, io.EOF
+	}
+	r.games++
+
+	g := &Game{Comments: map[int][]string{}, NAGs: map[int][]string{}}
+	var tags []game.Tag
+	fail := func(t token, format string, args ...interface{}) (*Game, error) {
+		r.skipGame(t)
+		return nil, &Error{Game: r.games, Line: t.line, Err: fmt.Errorf(format, args...)}
+	}
+
+	for ; ok && t.kind == TAG; t, ok, err = r.nextToken() {
+		tags = append(tags, game.Tag{Name: t.text, Value: t.value})
+	}
+	if err != nil {
+		return nil, &Error{Game: r.games, Line: r.lexer.line, Err: err}
+	}
+
+	start := board.GetStartBoard()
+	for _, tag := range tags {
+		if tag.Name == "FEN" {
+			start, err = board.ParseFEN(tag.Value)
+			if err != nil {
+				return fail(t, "invalid FEN tag: %v", err)
+			}
+		}
+	}
+	g.Game = game.InitGameFromPosition(start)
+	for _, tag := range tags {
+		g.Game.SetTag(tag.Name, tag.Value)
+	}
+
+	// every open variation keeps its position and the one before its last move, where a nested variation starts
+	type line struct {
+		position board.BitBoard
+		previous board.BitBoard
+		ply      int
+		moves    int
+	}
+	var variations []line
+
+	for ; ok; t, ok, err = r.nextToken() {
+		switch t.kind {
+		case TAG:
+			// the result is missing, the tag belongs to the next game
+			r.pending = &t
+			return g, nil
+		case RESULT:
+			if len(variations) > 0 {
+				return fail(t, "result %s inside a variation", t.text)
+			}
+			g.Game.SetResult(parseResult(t.text))
+			return g, nil
+		case COMMENT:
+			if len(variations) == 0 {
+				ply := g.Game.Ply()
+				g.Comments[ply] = append(g.Comments[ply], t.text)
+				g.Game.SetComment(ply, strings.Join(g.Comments[ply], " "))
+			}
+		case NAG:
+			if len(variations) == 0 {
+				g.NAGs[g.Game.Ply()] = append(g.NAGs[g.Game.Ply()], t.text)
+			}
+		case VARIATION_START:
+			var variation line
+			if len(variations) == 0 {
+				if g.Game.Ply() == 0 {
+					return fail(t, "variation before the first move")
+				}
+				positions := g.Game.Positions()
+				variation = line{position: positions[len(positions)-2], ply: g.Game.Ply() - 1}
+			} else {
+				parent := variations[len(variations)-1]
+				if parent.moves == 0 {
+					return fail(t, "variation before the first move")
+				}
+				variation = line{position: parent.previous, ply: parent.ply - 1}
+			}
+			variations = append(variations, variation)
+		case VARIATION_END:
+			if len(variations) == 0 {
+				return fail(t, "variation closed without being opened")
+			}
+			variations = variations[:len(variations)-1]
+		case MOVE:
+			if len(variations) == 0 {
+				position := g.Game.Position()
+				move, err := position.ParseSAN(t.text)
+				if err == nil {
+					err = g.Game.MakeMove(move)
+				}
+				if err != nil {
+					r.skipGame(t)
+					return nil, &Error{Game: r.games, Line: t.line, Ply: g.Game.Ply() + 1, Move: t.text,
+						Turn: position.GetTurn(), White: position.IsWhitesTurn(), Err: err}
+				}
+				continue
+			}
+
+			variation := &variations[len(variations)-1]
+			move, err := variation.position.ParseSAN(t.text)
+			if err != nil {
+				r.skipGame(t)
+				return nil, &Error{Game: r.games, Line: t.line, Ply: variation.ply + 1, Move: t.text,
+					Turn: variation.position.GetTurn(), White: variation.position.IsWhitesTurn(), Err: err}
+			}
+			variation.previous = variation.position
+			variation.position.MakeMove(move)
+			variation.ply++
+			variation.moves++
+		}
+	}
+	if err != nil {
+		return nil, &Error{Game: r.games, Line: r.lexer.line, Err: err}
+	}
+
+	if len(variations) > 0 {
+		return nil, &Error{Game: r.games, Line: r.lexer.line, Err: fmt.Errorf("variation is not closed")}
+	}
+	// the input ended without a result
+	return g, nil
+}
+
+// skipGame drops the tokens up to the end of the game that contains t
+func (r *Reader) skipGame(t token) {
+	if t.kind == RESULT {
+		return
+	}
+	inMovetext := t.kind != TAG
+	for {
+		t, ok, err := r.nextToken()
+		if !ok || err != nil || t.kind == RESULT {
+			return
+		}
+		if t.kind == TAG && inMovetext {
+			r.pending = &t
+			return
+		}
+		if t.kind != TAG {
+			inMovetext = true
+		}
+	}
+}
+
+func parseResult(text string) game.Result {
+	switch text {
+	case "1-0":
+		return game.WHITE_WINS
+	case "0-1":
+		return game.BLACK_WINS
+	case "1/2-1/2":
+		return game.DRAW
+	}
+	return game.ONGOING
+}
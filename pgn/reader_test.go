@@ -0,0 +1,187 @@
+package pgn
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"terrible_chess_computer/board"
+	"terrible_chess_computer/game"
+)
+
+const annotated = `[Event "Casual game"]
+[Site "London"]
+[Date "1851.06.21"]
+[Round "?"]
+[White "Anderssen, Adolf"]
+[Black "Kieseritzky, Lionel"]
+[Result "1-0"]
+
+{The immortal game} 1. e4 e5 2. f4 exf4 3. Bc4 Qh4+ 4. Kf1 b5 $2 5. Bxb5 Nf6
+6. Nf3 Qh6 7. d3 Nh5 8. Nh4 Qg5 9. Nf5 c6 10. g4 Nf6 11. Rg1 cxb5 12. h4 Qg6
+13. h5 Qg5 14. Qf3 Ng8 15. Bxf4 Qf6 16. Nc3 Bc5 17. Nd5 Qxb2 18. Bd6 Bxg1
+(18... Qxa1+ 19. Ke2 Qb2 (19... Qxg1 20. Nxg7+) 20. Kd2) 19. e5 Qxa1+ 20. Ke2
+Na6 21. Nxg7+ Kd8 22. Qf6+ Nxf6 23. Be7# ; mate
+1-0
+
+[Event "Second"]
+[FEN "4k3/8/8/8/8/8/4P3/4K3 w - - 0 1"]
+[SetUp "1"]
+
+1. e4 Kd7 2. e5 *
+`
+
+func TestReader_annotatedGames(t *testing.T) {
+	games, err := ReadAll(strings.NewReader(annotated))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(games) != 2 {
+		t.Fatalf("expected 2 games, got %d", len(games))
+	}
+
+	first := games[0]
+	if first.Game.GetTag("White") != "Anderssen, Adolf" || first.Game.GetTag("Missing") != "" || len(first.Game.Tags()) != 7 {
+		t.Error("tags aren't read correctly")
+	}
+	if first.Game.GetResult() != game.WHITE_WINS {
+		t.Error("result should be 1-0")
+	}
+	if first.Game.Ply() != 45 {
+		t.Errorf("expected 45 plies, got %d", first.Game.Ply())
+	}
+	if first.Game.Outcome().Result != game.WHITE_WINS {
+		t.Error("the game should end in mate")
+	}
+	if first.Game.GetComment(0) != "The immortal game" {
+		t.Error("comment before the first move is missing")
+	}
+	if first.Game.GetComment(45) != "mate" {
+		t.Error("line comment after the last move is missing")
+	}
+	if len(first.Comments[45]) != 1 || len(first.Comments[0]) != 1 {
+		t.Error("comments should be kept one by one")
+	}
+	if len(first.NAGs[8]) != 1 || first.NAGs[8][0] != "2" {
+		t.Error("NAG after 4... b5 is missing")
+	}
+
+	second := games[1]
+	if second.Game.GetResult() != game.ONGOING || second.Game.Ply() != 3 {
+		t.Error("second game isn't read correctly")
+	}
+	start := second.Game.StartPosition()
+	if start.SamePosition(board.GetStartBoard()) {
+		t.Error("FEN tag should set the start position")
+	}
+}
+
+func TestReader_illegalMove(t *testing.T) {
+	input := "[Event \"broken\"]\n\n1. e4 e5 2. Nf3 Nc6 3. Bb5 Ke7 4. O-O Kd6 5. Bxe8 *\n\n" +
+		"[Event \"fine\"]\n\n1. d4 d5 *\n"
+	reader, err := NewReader(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = reader.Next()
+	var pgnError *Error
+	if !errors.As(err, &pgnError) {
+		t.Fatalf("expected a pgn error, got %v", err)
+	}
+	if pgnError.Game != 1 || pgnError.Ply != 9 || pgnError.Move != "Bxe8" || pgnError.Line != 3 {
+		t.Errorf("error should point at ply 9, got %v", pgnError)
+	}
+	if !strings.Contains(err.Error(), "5. Bxe8") || !strings.Contains(err.Error(), "illegal move") {
+		t.Errorf("error should name the move and the reason, got %v", err)
+	}
+
+	g, err := reader.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.Game.GetTag("Event") != "fine" || g.Game.Ply() != 2 {
+		t.Error("the game after the broken one should be read")
+	}
+	if _, err := reader.Next(); err != io.EOF {
+		t.Error("expected io.EOF after the last game")
+	}
+}
+
+func TestReader_illegalVariation(t *testing.T) {
+	_, err := ReadAll(strings.NewReader("1. e4 e5 (1... Nf3) 2. Nf3 *"))
+	var pgnError *Error
+	if !errors.As(err, &pgnError) || pgnError.Ply != 2 || pgnError.Move != "Nf3" {
+		t.Errorf("illegal move in a variation should fail at ply 2, got %v", err)
+	}
+
+	_, err = ReadAll(strings.NewReader("1. e4 ( ( 1. d4 ) 1. c4 ) e5 *"))
+	if !errors.As(err, &pgnError) || pgnError.Move != "" || !strings.Contains(err.Error(), "variation before the first move") {
+		t.Errorf("nested variation before the first move of its parent should fail, got %v", err)
+	}
+
+	for _, input := range []string{"(1. e4) *", "1. e4 ) *", "1. e4 (1. d4 *", "1. e4 (1. d4"} {
+		if _, err := ReadAll(strings.NewReader(input)); err == nil {
+			t.Error("expected an error for " + input)
+		}
+	}
+}
+
+func TestReader_missingResult(t *testing.T) {
+	games, err := ReadAll(strings.NewReader("[Event \"a\"]\n1. e4\n[Event \"b\"]\n1. d4 1/2-1/2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(games) != 2 || games[0].Game.GetResult() != game.ONGOING || games[1].Game.GetResult() != game.DRAW {
+		t.Error("a tag should start the next game when the result is missing")
+	}
+}
+
+func TestReader_roundTrip(t *testing.T) {
+	games, err := ReadAll(strings.NewReader(annotated))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, g := range games {
+		again, err := ReadAll(strings.NewReader(g.Game.PGN()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(again) != 1 || again[0].Game.PGN() != g.Game.PGN() {
+			t.Errorf("exported game doesn't read back the same:\n%s", g.Game.PGN())
+		}
+	}
+}
+
+func TestReader_illegalMoveFromPosition(t *testing.T) {
+	input := "[FEN \"8/8/8/4k3/8/8/4P3/4K3 b - - 0 30\"]\n\n30... Kd5 31. Ke5 *"
+	_, err := ReadAll(strings.NewReader(input))
+	var pgnError *Error
+	if !errors.As(err, &pgnError) || pgnError.Ply != 2 || pgnError.Turn != 31 || !pgnError.White {
+		t.Fatalf("error should point at move 31 of white, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "(31. Ke5)") {
+		t.Errorf("error should number the move from the FEN, got %v", err)
+	}
+
+	_, err = ReadAll(strings.NewReader("[FEN \"8/8/8/4k3/8/8/4P3/4K3 b - - 0 30\"]\n\n30... Kd5 (30... Kd3) *"))
+	if err == nil || !strings.Contains(err.Error(), "(30... Kd3)") {
+		t.Errorf("error in a variation should number the move from the FEN, got %v", err)
+	}
+}
+
+func TestReader_commentsAtSamePly(t *testing.T) {
+	games, err := ReadAll(strings.NewReader("1. e4 {first} {second} ; third\n e5 *"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	comments := games[0].Comments[1]
+	if len(comments) != 3 || comments[0] != "first" || comments[1] != "second" || comments[2] != "third" {
+		t.Errorf("comments after 1. e4 should stay apart, got %q", comments)
+	}
+	if games[0].Game.GetComment(1) != "first second third" {
+		t.Errorf("the game should get the joined comments, got %q", games[0].Game.GetComment(1))
+	}
+}
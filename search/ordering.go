@@ -0,0 +1,112 @@
+package search
+
+import (
+	"terrible_chess_computer/board"
+	"terrible_chess_computer/eval"
+)
+
+// move ordering scores, every group is tried before the next one. Captures losing material come last.
+const (
+	HASH_MOVE_SCORE   = 1 << 30
+	CAPTURE_SCORE     = 1 << 24
+	KILLER_SCORE      = 1 << 20
+	BAD_CAPTURE_SCORE = -(1 << 24)
+	// history scores are halved once one gets above this, so they stay below the killers
+	MAX_HISTORY = 1 << 16
+)
+
+// killers are quiet moves that caused a cutoff in a sibling node, there are two per ply
+type killers [MAX_DEPTH + 1][2]board.Move
+
+// history counts for every piece and target field how often a quiet move caused a cutoff, weighted by depth
+type history [12][64]int
+
+// mvvLva orders captures by most valuable victim first and least valuable attacker second.
+// The king has no value and so comes first among the attackers, which is right since it can only take undefended pieces.
+func mvvLva(position *board.BitBoard, move board.Move) int {
+	victim := position.GetPieceOnField(move.ToX, move.ToY)
+	if move.IsEnPassant() {
+		victim = board.WHITE_PAWN
+	}
+	attacker := position.GetPieceOnField(move.FromX, move.FromY)
+
+	score := eval.PieceValue(victim)*16 - eval.PieceValue(attacker)/10
+	if move.IsPromotion() {
+		score += eval.PieceValue(move.Promotion) * 16
+	}
+	return score
+}
+
+func (s *Searcher) scoreMove(position *board.BitBoard, move, hashMove board.Move, ply int) int {
+	switch {
+	case move == hashMove:
+		return HASH_MOVE_SCORE
+	case move.IsCapture() || move.IsPromotion():
+		if position.SEE(move) < 0 {
+			return BAD_CAPTURE_SCORE + mvvLva(position, move)
+		}
+		return CAPTURE_SCORE + mvvLva(position, move)
+	case move == s.killers[ply][0]:
+		return KILLER_SCORE + 1
+	case move == s.killers[ply][1]:
+		return KILLER_SCORE
+	}
+
+	piece := position.GetPieceOnField(move.FromX, move.FromY)
+	return s.history[piece][move.ToY*8+move.ToX]
+}
+
+// orderMoves sorts moves by their score, best first
+func (s *Searcher) orderMoves(position *board.BitBoard, moves []board.Move, hashMove board.Move, ply int) {
+	scores := make([]int, len(moves))
+	for i, move := range moves {
+		scores[i] = s.scoreMove(position, move, hashMove, ply)
+	}
+	sortMoves(moves, scores)
+}
+
+func orderCaptures(position *board.BitBoard, moves []board.Move) {
+	scores := make([]int, len(moves))
+	for i, move := range moves {
+		scores[i] = mvvLva(position, move)
+	}
+	sortMoves(moves, scores)
+}
+
+// sortMoves is an insertion sort, move lists are short and mostly nearly sorted
+func sortMoves(moves []board.Move, scores []int) {
+	for i := 1; i < len(moves); i++ {
+		move, score := moves[i], scores[i]
+		j := i
+		for ; j > 0 && scores[j-1] < score; j-- {
+			moves[j], scores[j] = moves[j-1], scores[j-1]
+		}
+		moves[j], scores[j] = move, score
+	}
+}
+
+// storeCutoff remembers a quiet move that caused a beta cutoff, for the killers and the history table
+func (s *Searcher) storeCutoff(position *board.BitBoard, move board.Move, depth, ply int) {
+	if move.IsCapture() || move.IsPromotion() {
+		return
+	}
+
+	if s.killers[ply][0] != move {
+		s.killers[ply][1] = s.killers[ply][0]
+		s.killers[ply][0] = move
+	}
+
+	piece := position.GetPieceOnField(move.FromX, move.FromY)
+	s.history[piece][move.ToY*8+move.ToX] += depth * depth
+	if s.history[piece][move.ToY*8+move.ToX] > MAX_HISTORY {
+		s.ageHistory()
+	}
+}
+
+func (s *Searcher) ageHistory() {
+	for piece := range s.history {
+		for square := range s.history[piece] {
+			s.history[piece][square] /= 2
+		}
+	}
+}
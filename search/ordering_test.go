@@ -0,0 +1,85 @@
+package search
+
+import (
+	"testing"
+
+	"terrible_chess_computer/board"
+)
+
+func TestSearcher_OrderMoves(t *testing.T) {
+	// white can take the queen with the pawn, or the defended pawn with the rook
+	position := board.FromFEN("4k3/8/8/2pq4/4P3/8/8/2R1K3 w - - 0 1")
+	moves := position.LegalMoves()
+
+	searcher := NewSearcher()
+	searcher.killers[3][0] = board.NewMove(2, 0, 0, 0)
+	searcher.history[board.WHITE_KING][1*8+5] = 100
+
+	searcher.orderMoves(&position, moves, board.NewMove(2, 0, 1, 0), 3)
+
+	// hash move, captures, killer, history
+	for i, text := range []string{"c1b1", "e4d5", "c1a1", "e1f2"} {
+		if moves[i].String() != text {
+			t.Fatalf("expected %s at %d, got %v", text, i, moves)
+		}
+	}
+
+	// the queen takes back on c5
+	if last := moves[len(moves)-1]; last.String() != "c1c5" {
+		t.Errorf("losing capture should come last, got %v", moves)
+	}
+}
+
+func TestMvvLva(t *testing.T) {
+	position := board.FromFEN("4k3/8/8/2pq4/4P3/8/8/2R1K3 w - - 0 1")
+	pawnTakesQueen := board.Move{FromX: 4, FromY: 3, ToX: 3, ToY: 4, Promotion: board.NO_PIECE, Flags: board.CAPTURE}
+	rookTakesPawn := board.Move{FromX: 2, FromY: 0, ToX: 2, ToY: 4, Promotion: board.NO_PIECE, Flags: board.CAPTURE}
+	if mvvLva(&position, pawnTakesQueen) <= mvvLva(&position, rookTakesPawn) {
+		t.Error("taking the queen should come first")
+	}
+
+	position = board.FromFEN("k7/8/8/8/3q4/2P1R3/8/4K3 w - - 0 1")
+	pawnTakes := board.Move{FromX: 2, FromY: 2, ToX: 3, ToY: 3, Promotion: board.NO_PIECE, Flags: board.CAPTURE}
+	rookTakes := board.Move{FromX: 4, FromY: 2, ToX: 3, ToY: 3, Promotion: board.NO_PIECE, Flags: board.CAPTURE}
+	if mvvLva(&position, pawnTakes) <= mvvLva(&position, rookTakes) {
+		t.Error("the least valuable attacker should take first")
+	}
+}
+
+func TestSearcher_StoreCutoff(t *testing.T) {
+	position := board.GetStartBoard()
+	searcher := NewSearcher()
+	first := board.NewMove(6, 0, 5, 2)
+	second := board.NewMove(1, 0, 2, 2)
+
+	searcher.storeCutoff(&position, first, 3, 2)
+	searcher.storeCutoff(&position, second, 3, 2)
+	searcher.storeCutoff(&position, second, 3, 2)
+	if searcher.killers[2][0] != second || searcher.killers[2][1] != first {
+		t.Errorf("wrong killers %v", searcher.killers[2])
+	}
+	if searcher.history[board.WHITE_KNIGHT][2*8+5] != 9 || searcher.history[board.WHITE_KNIGHT][2*8+2] != 18 {
+		t.Error("history should grow with the square of the depth")
+	}
+
+	capture := board.Move{FromX: 4, FromY: 1, ToX: 3, ToY: 2, Promotion: board.NO_PIECE, Flags: board.CAPTURE}
+	searcher.storeCutoff(&position, capture, 3, 2)
+	if searcher.killers[2][0] == capture {
+		t.Error("captures are no killers")
+	}
+
+	for i := 0; i < MAX_HISTORY; i += 64 {
+		searcher.storeCutoff(&position, first, 8, 2)
+	}
+	if searcher.history[board.WHITE_KNIGHT][2*8+5] > MAX_HISTORY {
+		t.Error("history should be aged before it grows too large")
+	}
+}
+
+func TestSearcher_OrderingSavesNodes(t *testing.T) {
+	position := board.FromFEN("r4rk1/1pp1qppp/p1np1n2/2b1p1B1/2B1P1b1/P1NP1N2/1PP1QPPP/R4RK1 w - - 0 10")
+	result := NewSearcher().Search(position, Limits{Depth: 4}, nil)
+	if result.Nodes > 200000 {
+		t.Errorf("searched %d nodes for depth 4", result.Nodes)
+	}
+}
@@ -0,0 +1,287 @@
+package search
+
+import (
+	"time"
+
+	"terrible_chess_computer/board"
+	"terrible_chess_computer/eval"
+)
+
+const (
+	INFINITY  = 1000000
+	MATE      = 100000
+	MAX_DEPTH = 64
+)
+
+// IsMateScore reports whether score means a forced mate for one of the sides
+func IsMateScore(score int) bool {
+	return score > MATE-MAX_DEPTH*2 || score < -MATE+MAX_DEPTH*2
+}
+
+// MateIn converts a mate score to moves until mate, negative if the side to move gets mated
+func MateIn(score int) int {
+	if score > 0 {
+		return (MATE - score + 1) / 2
+	}
+	return -(MATE + score) / 2
+}
+
+// Limits of zero value are ignored, a search without any limit runs until it is stopped
+type Limits struct {
+	Depth    int
+	Nodes    uint64
+	MoveTime time.Duration
+}
+
+type Result struct {
+	Move  board.Move
+	Score int
+	Depth int
+	Nodes uint64
+	Time  time.Duration
+	PV    []board.Move
+	// per mill of the transposition table filled by this search
+	Hashfull int
+}
+
+type Searcher struct {
+	// OnIteration is called with the result of every completed iteration
+	OnIteration func(Result)
+
+	limits   Limits
+	start    time.Time
+	deadline time.Time
+	stop     <-chan struct{}
+	stopped  bool
+	nodes    uint64
+	rootMove board.Move
+	tt       *TranspositionTable
+	killers  killers
+	history  history
+}
+
+func NewSearcher() *Searcher {
+	return &Searcher{tt: NewTranspositionTable(DEFAULT_HASH_SIZE)}
+}
+
+// SetHashSize replaces the transposition table by an empty one of sizeMB megabytes
+func (s *Searcher) SetHashSize(sizeMB int) {
+	s.tt = NewTranspositionTable(sizeMB)
+}
+
+// ClearHash forgets everything learned in earlier searches, e.g. when a new game starts
+func (s *Searcher) ClearHash() {
+	s.tt.Clear()
+}
+
+// Search runs iterative deepening on position until one of the limits is hit or stop is closed.
+// The first iteration always completes so there is a move to play.
+func (s *Searcher) Search(position board.BitBoard, limits Limits, stop <-chan struct{}) Result {
+	s.limits = limits
+	s.start = time.Now()
+	s.deadline = time.Time{}
+	if limits.MoveTime > 0 {
+		s.deadline = s.start.Add(limits.MoveTime)
+	}
+	s.stop = stop
+	s.stopped = false
+	s.nodes = 0
+	s.rootMove = board.Move{}
+	s.tt.NewSearch()
+	s.killers = killers{}
+	s.ageHistory()
+
+	maxDepth := limits.Depth
+	if maxDepth <= 0 || maxDepth > MAX_DEPTH {
+		maxDepth = MAX_DEPTH
+	}
+
+	var result Result
+	for depth := 1; depth <= maxDepth; depth++ {
+		var pv []board.Move
+		score := s.negamax(&position, depth, 0, -INFINITY, INFINITY, &pv)
+
+		if s.stopped && depth > 1 {
+			break
+		}
+
+		result = Result{Score: score, Depth: depth, Nodes: s.nodes, Time: time.Since(s.start), PV: pv, Hashfull: s.tt.Hashfull()}
+		if len(pv) > 0 {
+			result.Move = pv[0]
+			s.rootMove = pv[0]
+		}
+		if s.OnIteration != nil {
+			s.OnIteration(result)
+		}
+
+		if s.stopped || len(pv) == 0 || IsMateScore(score) && MateIn(score) > 0 && MateIn(score)*2-1 <= depth {
+			break
+		}
+	}
+
+	result.Nodes = s.nodes
+	result.Time = time.Since(s.start)
+	result.Hashfull = s.tt.Hashfull()
+	return result
+}
+
+func (s *Searcher) checkLimits() {
+	if s.limits.Nodes > 0 && s.nodes >= s.limits.Nodes {
+		s.stopped = true
+		return
+	}
+
+	if s.nodes%1024 != 0 {
+		return
+	}
+
+	if !s.deadline.IsZero() && time.Now().After(s.deadline) {
+		s.stopped = true
+		return
+	}
+
+	select {
+	case <-s.stop:
+		s.stopped = true
+	default:
+	}
+}
+
+func (s *Searcher) negamax(position *board.BitBoard, depth, ply, alpha, beta int, pv *[]board.Move) int {
+	s.nodes++
+	// the first iteration has to finish, everything after may be cut off
+	if s.rootMove != (board.Move{}) {
+		s.checkLimits()
+		if s.stopped {
+			return 0
+		}
+	}
+
+	if ply > 0 && position.GetHalfmove() >= 100 {
+		return 0
+	}
+
+	var hashMove board.Move
+	if entry, ok := s.tt.Probe(position.GetHash()); ok {
+		hashMove = entry.Move
+		score := scoreFromTable(entry.Score, ply)
+		// exact scores inside the window are searched again, cutting there would cut off the principal variation
+		if ply > 0 && entry.Depth >= depth &&
+			(entry.Bound == LOWER_BOUND && score >= beta || entry.Bound == UPPER_BOUND && score <= alpha ||
+				entry.Bound == EXACT && (score >= beta || score <= alpha)) {
+			return score
+		}
+	}
+
+	moves := position.LegalMoves()
+	if len(moves) == 0 {
+		if position.IsCheck(position.IsWhitesTurn()) {
+			return -MATE + ply
+		}
+		return 0
+	}
+
+	if depth <= 0 {
+		return s.quiescence(position, alpha, beta)
+	}
+
+	if ply == 0 && s.rootMove != (board.Move{}) {
+		hashMove = s.rootMove
+	}
+	s.orderMoves(position, moves, hashMove, ply)
+
+	originalAlpha := alpha
+	var bestMove board.Move
+	for _, move := range moves {
+		var childPV []board.Move
+
+		undo := position.MakeMove(move)
+		score := -s.negamax(position, depth-1, ply+1, -beta, -alpha, &childPV)
+		position.UnmakeMove(move, undo)
+
+		if s.stopped {
+			return 0
+		}
+
+		if score > alpha {
+			alpha = score
+			bestMove = move
+			*pv = append([]board.Move{move}, childPV...)
+			if alpha >= beta {
+				s.storeCutoff(position, move, depth, ply)
+				break
+			}
+		}
+	}
+
+	bound := EXACT
+	if alpha >= beta {
+		bound = LOWER_BOUND
+	} else if alpha <= originalAlpha {
+		bound = UPPER_BOUND
+	}
+	s.tt.Store(position.GetHash(), bestMove, scoreToTable(alpha, ply), depth, bound)
+
+	return alpha
+}
+
+// DELTA_MARGIN is added to the material a capture wins before it's skipped as hopeless
+const DELTA_MARGIN = 200
+
+// quiescence only looks at captures and promotions until the position is quiet, so that the evaluation
+// isn't taken in the middle of an exchange. The side to move may stand pat on the static evaluation instead.
+func (s *Searcher) quiescence(position *board.BitBoard, alpha, beta int) int {
+	standPat := eval.Evaluate(position)
+	if standPat >= beta {
+		return beta
+	}
+	if standPat > alpha {
+		alpha = standPat
+	}
+
+	moves := position.CaptureMoves()
+	orderCaptures(position, moves)
+	for _, move := range moves {
+		// delta pruning: even winning the piece for free doesn't reach alpha
+		gain := eval.PieceValue(position.GetPieceOnField(move.ToX, move.ToY))
+		if move.IsEnPassant() {
+			gain = eval.PieceValue(board.WHITE_PAWN)
+		}
+		if move.IsPromotion() {
+			gain += eval.PieceValue(move.Promotion) - eval.PieceValue(board.WHITE_PAWN)
+		}
+		if standPat+gain+DELTA_MARGIN <= alpha {
+			continue
+		}
+
+		// captures losing material won't improve on standing pat
+		if !move.IsPromotion() && position.SEE(move) < 0 {
+			continue
+		}
+
+		s.nodes++
+		if s.rootMove != (board.Move{}) {
+			s.checkLimits()
+			if s.stopped {
+				return 0
+			}
+		}
+
+		undo := position.MakeMove(move)
+		score := -s.quiescence(position, -beta, -alpha)
+		position.UnmakeMove(move, undo)
+
+		if s.stopped {
+			return 0
+		}
+
+		if score > alpha {
+			alpha = score
+			if alpha >= beta {
+				break
+			}
+		}
+	}
+
+	return alpha
+}
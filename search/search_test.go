@@ -0,0 +1,165 @@
+package search
+
+import (
+	"testing"
+	"time"
+
+	"terrible_chess_computer/board"
+	"terrible_chess_computer/eval"
+)
+
+func TestSearcher_MateInOne(t *testing.T) {
+	position := board.FromFEN("6k1/5ppp/8/8/8/8/8/R5K1 w - - 0 1")
+	result := NewSearcher().Search(position, Limits{Depth: 3}, nil)
+
+	if result.Move.String() != "a1a8" {
+		t.Errorf("expected Ra8#, got %s", result.Move)
+	}
+	if result.Score != MATE-1 || MateIn(result.Score) != 1 {
+		t.Errorf("expected mate in one score, got %d", result.Score)
+	}
+}
+
+func TestSearcher_MateInTwo(t *testing.T) {
+	position := board.FromFEN("7k/8/5K2/8/8/8/8/R7 w - - 0 1")
+	result := NewSearcher().Search(position, Limits{Depth: 4}, nil)
+
+	if MateIn(result.Score) != 2 {
+		t.Errorf("expected mate in two, got score %d with %v", result.Score, result.PV)
+	}
+	if len(result.PV) != 3 {
+		t.Errorf("principal variation should end in mate: %v", result.PV)
+	}
+}
+
+func TestSearcher_GetsMated(t *testing.T) {
+	position := board.FromFEN("k7/8/1K6/8/8/8/8/6R1 b - - 0 1")
+	result := NewSearcher().Search(position, Limits{Depth: 2}, nil)
+
+	if MateIn(result.Score) != -1 {
+		t.Errorf("black can't stop the mate, got score %d", result.Score)
+	}
+}
+
+func TestSearcher_WinsMaterial(t *testing.T) {
+	position := board.FromFEN("4k3/8/8/3q4/8/8/3R4/4K3 w - - 0 1")
+	result := NewSearcher().Search(position, Limits{Depth: 2}, nil)
+
+	if result.Move.String() != "d2d5" {
+		t.Errorf("the queen is hanging, got %s", result.Move)
+	}
+	if result.Score < 400 {
+		t.Errorf("white should be a rook up, got %d", result.Score)
+	}
+}
+
+func TestSearcher_PrincipalVariationIsLegal(t *testing.T) {
+	position := board.GetStartBoard()
+	result := NewSearcher().Search(position, Limits{Depth: 3}, nil)
+
+	if len(result.PV) != 3 || result.Depth != 3 {
+		t.Fatalf("expected a principal variation of 3 moves, got %v", result.PV)
+	}
+	for _, move := range result.PV {
+		legal := false
+		for _, legalMove := range position.LegalMoves() {
+			if legalMove == move {
+				legal = true
+			}
+		}
+		if !legal {
+			t.Fatalf("%s is not legal in %s", move, position.ToFEN())
+		}
+		position.MakeMove(move)
+	}
+}
+
+func TestSearcher_Limits(t *testing.T) {
+	position := board.GetStartBoard()
+
+	iterations := 0
+	searcher := NewSearcher()
+	searcher.OnIteration = func(result Result) {
+		iterations++
+	}
+	result := searcher.Search(position, Limits{Nodes: 500}, nil)
+	if result.Nodes > 500 {
+		t.Errorf("searched %d nodes instead of 500", result.Nodes)
+	}
+	if result.Move == (board.Move{}) || iterations != result.Depth {
+		t.Error("no move found within the node limit")
+	}
+
+	start := time.Now()
+	result = NewSearcher().Search(position, Limits{MoveTime: 50 * time.Millisecond}, nil)
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("search took %v with a limit of 50ms", elapsed)
+	}
+	if result.Move == (board.Move{}) {
+		t.Error("no move found within the time limit")
+	}
+
+	stop := make(chan struct{})
+	time.AfterFunc(50*time.Millisecond, func() { close(stop) })
+	start = time.Now()
+	result = NewSearcher().Search(position, Limits{}, stop)
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("search took %v after being stopped at 50ms", elapsed)
+	}
+	if result.Move == (board.Move{}) {
+		t.Error("no move found before being stopped")
+	}
+}
+
+func TestSearcher_NoMoves(t *testing.T) {
+	position := board.FromFEN("7k/5Q2/6K1/8/8/8/8/8 b - - 0 1")
+	result := NewSearcher().Search(position, Limits{Depth: 3}, nil)
+
+	if result.Move != (board.Move{}) || result.Score != 0 {
+		t.Errorf("stalemate has no move and a draw score, got %s %d", result.Move, result.Score)
+	}
+}
+
+func TestSearcher_TranspositionTable(t *testing.T) {
+	position := board.GetStartBoard()
+
+	searcher := NewSearcher()
+	first := searcher.Search(position, Limits{Depth: 4}, nil)
+	if first.Hashfull == 0 {
+		t.Error("search should fill the transposition table")
+	}
+
+	// the second search starts with everything the first one learned
+	second := searcher.Search(position, Limits{Depth: 4}, nil)
+	if second.Nodes >= first.Nodes || second.Move != first.Move || second.Score != first.Score {
+		t.Errorf("second search took %d nodes instead of %d, %s %d instead of %s %d",
+			second.Nodes, first.Nodes, second.Move, second.Score, first.Move, first.Score)
+	}
+
+	searcher.ClearHash()
+	searcher.SetHashSize(1)
+	if third := searcher.Search(position, Limits{Depth: 4}, nil); third.Score != first.Score {
+		t.Error("the table should not change the score")
+	}
+}
+
+func TestSearcher_Quiescence(t *testing.T) {
+	// the pawn is defended, a fixed depth of one would not see the recapture
+	position := board.FromFEN("4k3/8/4p3/3p4/8/8/8/3QK3 w - - 0 1")
+	result := NewSearcher().Search(position, Limits{Depth: 1}, nil)
+	if result.Move.String() == "d1d5" {
+		t.Errorf("queen takes a defended pawn, score %d", result.Score)
+	}
+
+	// standing pat: white doesn't have to start a losing exchange
+	searcher := NewSearcher()
+	if score := searcher.quiescence(&position, -INFINITY, INFINITY); score != eval.Evaluate(&position) {
+		t.Errorf("quiet score should be the static evaluation, got %d", score)
+	}
+
+	// a hanging queen is taken
+	position = board.FromFEN("4k3/8/8/3q4/8/8/3R4/4K3 w - - 0 1")
+	if score := searcher.quiescence(&position, -INFINITY, INFINITY); score < 400 {
+		t.Errorf("white should win the queen, got %d", score)
+	}
+}
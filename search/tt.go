@@ -0,0 +1,126 @@
+package search
+
+import (
+	"unsafe"
+
+	"terrible_chess_computer/board"
+)
+
+type Bound uint8
+
+const (
+	NO_BOUND Bound = iota
+	// the score is exact, it lies between alpha and beta
+	EXACT
+	// the search failed high, the real score is at least as good
+	LOWER_BOUND
+	// the search failed low, the real score is at most as good
+	UPPER_BOUND
+)
+
+const DEFAULT_HASH_SIZE = 16
+
+type Entry struct {
+	Move  board.Move
+	Score int
+	Depth int
+	Bound Bound
+
+	key uint64
+	age uint8
+}
+
+// TranspositionTable maps position hashes to search results, a key is stored in the slot given by its lowest bits
+type TranspositionTable struct {
+	entries []Entry
+	mask    uint64
+	age     uint8
+}
+
+// NewTranspositionTable creates a table with as many entries as fit into sizeMB megabytes, rounded down to a power of two
+func NewTranspositionTable(sizeMB int) *TranspositionTable {
+	count := uint64(sizeMB) * 1024 * 1024 / uint64(unsafe.Sizeof(Entry{}))
+	size := uint64(1)
+	for size*2 <= count {
+		size *= 2
+	}
+
+	return &TranspositionTable{entries: make([]Entry, size), mask: size - 1}
+}
+
+func (tt *TranspositionTable) GetSize() int {
+	return len(tt.entries)
+}
+
+func (tt *TranspositionTable) Clear() {
+	for i := range tt.entries {
+		tt.entries[i] = Entry{}
+	}
+	tt.age = 0
+}
+
+// NewSearch ages all entries, entries of older searches are replaced first
+func (tt *TranspositionTable) NewSearch() {
+	tt.age++
+}
+
+func (tt *TranspositionTable) Probe(key uint64) (Entry, bool) {
+	entry := tt.entries[key&tt.mask]
+	if entry.Bound == NO_BOUND || entry.key != key {
+		return Entry{}, false
+	}
+	return entry, true
+}
+
+// Store keeps entries of the current search that were searched deeper than the new one, everything else is replaced
+func (tt *TranspositionTable) Store(key uint64, move board.Move, score, depth int, bound Bound) {
+	entry := &tt.entries[key&tt.mask]
+	if entry.Bound != NO_BOUND && entry.key != key && entry.age == tt.age && entry.Depth > depth {
+		return
+	}
+
+	// a fail low doesn't know a best move, the one from an earlier search is still the best guess
+	if move == (board.Move{}) && entry.key == key {
+		move = entry.Move
+	}
+
+	*entry = Entry{Move: move, Score: score, Depth: depth, Bound: bound, key: key, age: tt.age}
+}
+
+// Hashfull samples the first thousand entries and reports how many per mill are used by the current search
+func (tt *TranspositionTable) Hashfull() int {
+	samples := 1000
+	if samples > len(tt.entries) {
+		samples = len(tt.entries)
+	}
+
+	used := 0
+	for _, entry := range tt.entries[:samples] {
+		if entry.Bound != NO_BOUND && entry.age == tt.age {
+			used++
+		}
+	}
+
+	return used * 1000 / samples
+}
+
+// mate scores count plies from the root, the table stores them counted from the position itself
+func scoreToTable(score, ply int) int {
+	if score > MATE-MAX_DEPTH*2 {
+		return score + ply
+	}
+	if score < -MATE+MAX_DEPTH*2 {
+		return score - ply
+	}
+	return score
+}
+
+func scoreFromTable(score, ply int) int {
+	if score > MATE-MAX_DEPTH*2 {
+		return score - ply
+	}
+	if score < -MATE+MAX_DEPTH*2 {
+		return score + ply
+	}
+	return score
+}
@@ -0,0 +1,94 @@
+package search
+
+import (
+	"testing"
+
+	"terrible_chess_computer/board"
+)
+
+func TestNewTranspositionTable(t *testing.T) {
+	tt := NewTranspositionTable(1)
+	size := tt.GetSize()
+	if size&(size-1) != 0 || size < 1024 {
+		t.Errorf("size should be a power of two, got %d", size)
+	}
+	if NewTranspositionTable(2).GetSize() != 2*size {
+		t.Error("twice the memory should give twice the entries")
+	}
+}
+
+func TestTranspositionTable_StoreAndProbe(t *testing.T) {
+	tt := NewTranspositionTable(1)
+	move := board.NewMove(4, 1, 4, 3)
+
+	if _, ok := tt.Probe(42); ok {
+		t.Error("empty table should not have an entry")
+	}
+
+	tt.Store(42, move, 17, 3, EXACT)
+	entry, ok := tt.Probe(42)
+	if !ok || entry.Move != move || entry.Score != 17 || entry.Depth != 3 || entry.Bound != EXACT {
+		t.Errorf("wrong entry %+v", entry)
+	}
+
+	// same slot, different key
+	other := uint64(42 + tt.GetSize())
+	if _, ok := tt.Probe(other); ok {
+		t.Error("entry of another key was returned")
+	}
+
+	// an upper bound has no best move, the old one is kept
+	tt.Store(42, board.Move{}, -5, 4, UPPER_BOUND)
+	if entry, _ := tt.Probe(42); entry.Move != move || entry.Bound != UPPER_BOUND {
+		t.Errorf("best move of the old entry should be kept, got %+v", entry)
+	}
+
+	tt.Clear()
+	if _, ok := tt.Probe(42); ok {
+		t.Error("cleared table should not have an entry")
+	}
+}
+
+func TestTranspositionTable_Replacement(t *testing.T) {
+	tt := NewTranspositionTable(1)
+	deep := uint64(7)
+	shallow := deep + uint64(tt.GetSize())
+
+	tt.Store(deep, board.Move{}, 0, 8, EXACT)
+	tt.Store(shallow, board.Move{}, 0, 2, EXACT)
+	if _, ok := tt.Probe(deep); !ok {
+		t.Error("deeper entry of the same search should be kept")
+	}
+
+	tt.NewSearch()
+	tt.Store(shallow, board.Move{}, 0, 2, EXACT)
+	if _, ok := tt.Probe(shallow); !ok {
+		t.Error("entries of an old search should be replaced")
+	}
+}
+
+func TestTranspositionTable_Hashfull(t *testing.T) {
+	tt := NewTranspositionTable(1)
+	for key := uint64(0); key < 500; key++ {
+		tt.Store(key, board.Move{}, 0, 1, EXACT)
+	}
+	if tt.Hashfull() != 500 {
+		t.Errorf("expected half of the samples to be used, got %d", tt.Hashfull())
+	}
+
+	tt.NewSearch()
+	if tt.Hashfull() != 0 {
+		t.Error("entries of old searches don't count")
+	}
+}
+
+func TestScoreTable(t *testing.T) {
+	// mate in 3 plies from the root, found at ply 2, is a mate in one from the stored position
+	score := MATE - 3
+	if stored := scoreToTable(score, 2); stored != MATE-1 || scoreFromTable(stored, 2) != score {
+		t.Errorf("wrong mate score conversion %d", stored)
+	}
+	if scoreToTable(-MATE+3, 2) != -MATE+1 || scoreToTable(150, 5) != 150 {
+		t.Error("wrong score conversion")
+	}
+}